@@ -0,0 +1,208 @@
+package sanpltxt
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is a monetary value in grosze (1/100 PLN).
+type Amount int64
+
+// Amount limits. MaxAmount is the largest amount the format can hold: 12
+// digits of zloty and 2 of grosze.
+const MaxAmount Amount = 999_999_999_999_99
+
+// Per-mode amount limits enforced by validation. They follow the limits
+// published by the bank.
+const (
+	MaxExpressElixirAmount Amount = 100_000_00
+	MinSORBNETAmount       Amount = 1_000_000_00
+)
+
+// String formats the amount as in the import file, e.g. "123,45" or "1000".
+// Negative amounts, which validation rejects, keep their sign.
+func (a Amount) String() string {
+	var b strings.Builder
+	if a < 0 {
+		b.WriteString("-")
+		a = -a
+	}
+	zloty := a / 100
+	grosze := a % 100
+	b.WriteString(strconv.FormatInt(int64(zloty), 10))
+	if grosze == 0 {
+		return b.String()
+	}
+	b.WriteString(",")
+	if grosze < 10 {
+		b.WriteString("0")
+	}
+	b.WriteString(strconv.FormatInt(int64(grosze), 10))
+	return b.String()
+}
+
+// ParseAmount parses a PLN amount written by a person or another system, such
+// as "1 234,56", "1234.56", "1.234,56", "1,234.56" or "PLN 12,00". A "PLN" or
+// "zł" currency marker, spaces and apostrophes used as thousands separators
+// and a leading sign are accepted. When both "." and "," appear, the last one
+// is the decimal separator; a separator that appears once is decimal and one
+// repeated is a thousands separator. More than two decimal places are
+// rejected, so "1.234" is an error rather than a guess.
+func ParseAmount(s string) (Amount, error) {
+	num := trimCurrency(strings.TrimSpace(s))
+
+	neg := false
+	if rest, ok := strings.CutPrefix(num, "-"); ok {
+		neg, num = true, rest
+	} else {
+		num = strings.TrimPrefix(num, "+")
+	}
+
+	num = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\'':
+			return -1
+		}
+		return r
+	}, num)
+
+	dec := decimalSeparator(num)
+	var intPart, fracPart string
+	if dec == 0 {
+		intPart = strings.NewReplacer(".", "", ",", "").Replace(num)
+	} else {
+		i := strings.LastIndexByte(num, dec)
+		intPart = strings.ReplaceAll(num[:i], string(separatorOther(dec)), "")
+		fracPart = num[i+1:]
+	}
+
+	if (intPart == "" && fracPart == "") || !isDigitsOnly(intPart) || !isDigitsOnly(fracPart) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(fracPart) > 2 {
+		return 0, fmt.Errorf("invalid amount %q: more than two decimal places", s)
+	}
+
+	var zloty int64
+	if intPart != "" {
+		var err error
+		if zloty, err = strconv.ParseInt(intPart, 10, 64); err != nil || zloty > math.MaxInt64/100-1 {
+			return 0, fmt.Errorf("invalid amount %q: out of range", s)
+		}
+	}
+	var grosze int64
+	if fracPart != "" {
+		grosze, _ = strconv.ParseInt(fracPart, 10, 64)
+		if len(fracPart) == 1 {
+			grosze *= 10
+		}
+	}
+
+	a := Amount(zloty*100 + grosze)
+	if neg {
+		a = -a
+	}
+	return a, nil
+}
+
+func trimCurrency(s string) string {
+	for _, c := range []string{"PLN", "zł", "zl"} {
+		if len(s) >= len(c) && strings.EqualFold(s[:len(c)], c) {
+			return strings.TrimSpace(s[len(c):])
+		}
+		if len(s) >= len(c) && strings.EqualFold(s[len(s)-len(c):], c) {
+			return strings.TrimSpace(s[:len(s)-len(c)])
+		}
+	}
+	return s
+}
+
+// decimalSeparator returns the decimal separator used in num, or 0 if the
+// amount has no fractional part.
+func decimalSeparator(num string) byte {
+	dot, comma := strings.LastIndexByte(num, '.'), strings.LastIndexByte(num, ',')
+	switch {
+	case dot >= 0 && comma >= 0:
+		if dot > comma {
+			return '.'
+		}
+		return ','
+	case dot >= 0 && strings.Count(num, ".") == 1:
+		return '.'
+	case comma >= 0 && strings.Count(num, ",") == 1:
+		return ','
+	}
+	return 0
+}
+
+func separatorOther(dec byte) byte {
+	if dec == '.' {
+		return ','
+	}
+	return '.'
+}
+
+// AmountFromRat converts r in PLN to an Amount without rounding. It fails if
+// r has a fractional part smaller than one grosz or does not fit an Amount.
+func AmountFromRat(r *big.Rat) (Amount, error) {
+	g := new(big.Rat).Mul(r, big.NewRat(100, 1))
+	if !g.IsInt() {
+		return 0, fmt.Errorf("amount %s has more than two decimal places", r.FloatString(10))
+	}
+	if !g.Num().IsInt64() {
+		return 0, fmt.Errorf("amount %s is out of range", r.FloatString(2))
+	}
+	return Amount(g.Num().Int64()), nil
+}
+
+// Rat returns the amount in PLN as a big.Rat.
+func (a Amount) Rat() *big.Rat {
+	return big.NewRat(int64(a), 100)
+}
+
+// MarshalText encodes the amount in PLN as a decimal with a dot and two
+// decimal places, such as "1234.56".
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.Rat().FloatString(2)), nil
+}
+
+// UnmarshalText decodes an amount in any form accepted by ParseAmount.
+func (a *Amount) UnmarshalText(text []byte) error {
+	v, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// MarshalJSON encodes the amount as a decimal string, such as "1234.56", so
+// that it survives decoders that read numbers as floats.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	text, _ := a.MarshalText()
+	return strconv.AppendQuote(nil, string(text)), nil
+}
+
+// UnmarshalJSON decodes an amount from a JSON string in any form accepted by
+// ParseAmount or from a JSON number, which is read exactly.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return fmt.Errorf("invalid amount %s", data)
+		}
+		return a.UnmarshalText([]byte(s))
+	}
+	if bytes.ContainsAny(data, ",eE") {
+		return fmt.Errorf("invalid amount %s", data)
+	}
+	return a.UnmarshalText(data)
+}
@@ -0,0 +1,114 @@
+package sanpltxt_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want sanpltxt.Amount
+	}{
+		{"1 234,56", 123456},
+		{"1234.56", 123456},
+		{"1.234,56", 123456},
+		{"1,234.56", 123456},
+		{"1.234.567", 123456700},
+		{"1 234 567,89", 123456789},
+		{"1'234.5", 123450},
+		{"PLN 12,00", 1200},
+		{"12,00 zł", 1200},
+		{"12 PLN", 1200},
+		{"0,01", 1},
+		{",5", 50},
+		{"1000", 100000},
+		{"-50,00", -5000},
+		{"+7", 700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := sanpltxt.ParseAmount(tt.in)
+			assert.NoError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseAmount_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"PLN",
+		"1,234", // ambiguous, three decimal places
+		"12.345",
+		"0,001",
+		"12a",
+		"1e3",
+		"--5",
+		"99999999999999999999",
+	} {
+		t.Run(in, func(t *testing.T) {
+			_, err := sanpltxt.ParseAmount(in)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAmount_JSON(t *testing.T) {
+	type row struct {
+		Amount sanpltxt.Amount `json:"amount"`
+	}
+
+	b, err := json.Marshal(row{Amount: 123456})
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), `{"amount":"1234.56"}`)
+
+	for in, want := range map[string]sanpltxt.Amount{
+		`{"amount":"1234.56"}`:   123456,
+		`{"amount":"1 234,56"}`:  123456,
+		`{"amount":1234.56}`:     123456,
+		`{"amount":0.1}`:         10,
+		`{"amount":"-0.05"}`:     -5,
+		`{"amount":null}`:        0,
+		`{"amount":"PLN 12,00"}`: 1200,
+	} {
+		var r row
+		assert.NoError(t, json.Unmarshal([]byte(in), &r))
+		assert.Equal(t, r.Amount, want)
+	}
+
+	var r row
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":1.005}`), &r))
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":1e2}`), &r))
+}
+
+func TestAmount_Text(t *testing.T) {
+	b, err := sanpltxt.Amount(-5).MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), "-0.05")
+
+	var a sanpltxt.Amount
+	assert.NoError(t, a.UnmarshalText([]byte("1 000,10")))
+	assert.Equal(t, a, sanpltxt.Amount(100010))
+}
+
+func TestAmountFromRat(t *testing.T) {
+	r, _ := new(big.Rat).SetString("1234.56")
+	a, err := sanpltxt.AmountFromRat(r)
+	assert.NoError(t, err)
+	assert.Equal(t, a, sanpltxt.Amount(123456))
+	assert.Equal(t, a.Rat().Cmp(r), 0)
+
+	_, err = sanpltxt.AmountFromRat(big.NewRat(1, 3))
+	assert.Error(t, err)
+
+	huge, _ := new(big.Rat).SetString("1e30")
+	_, err = sanpltxt.AmountFromRat(huge)
+	assert.Error(t, err)
+}
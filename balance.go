@@ -0,0 +1,105 @@
+package sanpltxt
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// Overdraft describes a debit account whose available balance does not cover
+// the transfers the package draws from it.
+type Overdraft struct {
+	Account   string // NRB of the debit account
+	Balance   Amount // available balance
+	Required  Amount // sum of transfers from the account
+	Shortfall Amount // Required - Balance
+
+	// Deferrable lists indexes into Package.Transfers, in the order they
+	// should be deferred, of transfers that together cover the shortfall.
+	Deferrable []int
+}
+
+// BalanceOptions controls CheckBalances.
+type BalanceOptions struct {
+	// Priority ranks transfers for deferral: transfers with a lower priority
+	// are deferred first. If nil, all transfers have the same priority.
+	Priority func(t Transfer) int
+}
+
+// CheckBalances compares the amounts the package draws from each debit
+// account with the available balances, keyed by NRB in any form accepted by
+// NormalizeNRB. Accounts missing from balances are treated as empty. It
+// returns the overdrawn accounts sorted by NRB, or nil if every account is
+// covered.
+//
+// Deferrable transfers are chosen in order of priority, then by execution
+// date with the latest first (transfers without a date go last) and then by
+// position in the package, last first, until the shortfall is covered.
+func (p *Package) CheckBalances(balances map[string]Amount, opts *BalanceOptions) []Overdraft {
+	available := make(map[string]Amount, len(balances))
+	for nrb, a := range balances {
+		available[NormalizeNRB(nrb)] += a
+	}
+
+	type entry struct {
+		index, priority int
+		info            transferInfo
+	}
+	byAccount := make(map[string][]entry)
+	for i, t := range p.transfers {
+		e := entry{index: i, info: infoOf(t)}
+		if opts != nil && opts.Priority != nil {
+			e.priority = opts.Priority(t)
+		}
+		byAccount[e.info.debit] = append(byAccount[e.info.debit], e)
+	}
+
+	var overdrafts []Overdraft
+	for _, account := range slices.Sorted(maps.Keys(byAccount)) {
+		entries := byAccount[account]
+
+		o := Overdraft{Account: account, Balance: available[account]}
+		for _, e := range entries {
+			o.Required += e.info.amount
+		}
+		if o.Required <= o.Balance {
+			continue
+		}
+		o.Shortfall = o.Required - o.Balance
+
+		slices.SortFunc(entries, func(a, b entry) int {
+			if c := cmp.Compare(a.priority, b.priority); c != 0 {
+				return c
+			}
+			if c := compareDates(b.info, a.info); c != 0 {
+				return c
+			}
+			return cmp.Compare(b.index, a.index)
+		})
+		var deferred Amount
+		for _, e := range entries {
+			if deferred >= o.Shortfall {
+				break
+			}
+			o.Deferrable = append(o.Deferrable, e.index)
+			deferred += e.info.amount
+		}
+
+		overdrafts = append(overdrafts, o)
+	}
+	return overdrafts
+}
+
+// compareDates orders transfers by execution date, treating transfers
+// without a date as executed immediately, before any dated transfer.
+func compareDates(a, b transferInfo) int {
+	switch {
+	case a.date == nil && b.date == nil:
+		return 0
+	case a.date == nil:
+		return -1
+	case b.date == nil:
+		return 1
+	}
+	return a.date.Compare(*b.date)
+}
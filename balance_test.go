@@ -0,0 +1,81 @@
+package sanpltxt_test
+
+import (
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func balancePackage() *sanpltxt.Package {
+	standard := func(debit string, amount sanpltxt.Amount, title string, d int) *sanpltxt.Standard {
+		s := &sanpltxt.Standard{
+			DebitAccount:  debit,
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jerzy Kowalski",
+			Address:       "Warszawa ul. Kaliska 123 00-123",
+			Amount:        amount,
+			Mode:          sanpltxt.ModeElixir,
+			Title:         title,
+		}
+		if d > 0 {
+			s.Date = date(2020, 9, d)
+		}
+		return s
+	}
+	return sanpltxt.NewPackage(1, []sanpltxt.Transfer{
+		standard("51109010430000000100111111", 50000, "czynsz", 0),
+		standard("51109010430000000100111111", 20000, "media", 10),
+		standard("51109010430000000100111111", 30000, "prowizja", 5),
+		standard("51109010430000000100111111", 10000, "pilne", 10),
+		standard("88102055581111103350100011", 10000, "zaliczka", 0),
+	}, nil)
+}
+
+func TestPackage_CheckBalances(t *testing.T) {
+	pkg := balancePackage()
+
+	assert.Nil(t, pkg.CheckBalances(map[string]sanpltxt.Amount{
+		"51109010430000000100111111":         110000,
+		"PL88 1020 5558 1111 1033 5010 0011": 10000,
+	}, nil))
+
+	got := pkg.CheckBalances(map[string]sanpltxt.Amount{
+		"51109010430000000100111111": 75000,
+	}, nil)
+	assert.DeepEqual(t, got, []sanpltxt.Overdraft{
+		{
+			Account:    "51109010430000000100111111",
+			Balance:    75000,
+			Required:   110000,
+			Shortfall:  35000,
+			Deferrable: []int{3, 1, 2},
+		},
+		{
+			Account:    "88102055581111103350100011",
+			Required:   10000,
+			Shortfall:  10000,
+			Deferrable: []int{4},
+		},
+	})
+}
+
+func TestPackage_CheckBalances_Priority(t *testing.T) {
+	pkg := balancePackage()
+
+	got := pkg.CheckBalances(map[string]sanpltxt.Amount{
+		"51109010430000000100111111": 75000,
+		"88102055581111103350100011": 10000,
+	}, &sanpltxt.BalanceOptions{
+		Priority: func(t sanpltxt.Transfer) int {
+			if t.(*sanpltxt.Standard).Title == "pilne" {
+				return 1
+			}
+			return 0
+		},
+	})
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].Shortfall, sanpltxt.Amount(35000))
+	assert.DeepEqual(t, got[0].Deferrable, []int{1, 2})
+}
@@ -0,0 +1,84 @@
+package sanpltxt
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"strings"
+	"sync"
+)
+
+// The embedded table is a hand-maintained seed listing the main commercial
+// banks by their 4-digit codes only, without branches. To get every bank and
+// branch, download the settlement number list published by NBP as
+// plewibnra.txt next to this file (it is not kept in the repository) and run
+// go generate, which replaces the table with the output of internal/genbanks.
+//go:generate go run ./internal/genbanks -o banks.csv plewibnra.txt
+
+//go:embed banks.csv
+var banksCSV string
+
+// Bank identifies the bank and branch that hold an account.
+type Bank struct {
+	SortCode string // settlement number: 8 digits, or 4 digits when the branch is unknown
+	Name     string
+	Branch   string
+	BIC      string
+}
+
+var banks = sync.OnceValue(func() map[string]Bank {
+	m, err := parseBanks(banksCSV)
+	if err != nil {
+		panic("sanpltxt: malformed banks.csv: " + err.Error())
+	}
+	return m
+})
+
+// parseBanks reads a bank table in the format written by internal/genbanks.
+func parseBanks(table string) (map[string]Bank, error) {
+	r := csv.NewReader(strings.NewReader(table))
+	r.Comma = ';'
+	r.Comment = '#'
+	r.FieldsPerRecord = 4
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]Bank, len(records))
+	for _, rec := range records {
+		m[rec[0]] = Bank{SortCode: rec[0], Name: rec[1], Branch: rec[2], BIC: rec[3]}
+	}
+	return m, nil
+}
+
+// BankInfo returns the bank holding the account nrb, identified by the sort
+// code in digits 3-10. Spaces and a leading "PL" country code are accepted.
+// If the branch is not in the table, the bank is looked up by its 4-digit
+// code and Branch is empty.
+//
+// The bundled table lists the main commercial banks without their branches,
+// so Branch is always empty and accounts at other banks, such as cooperative
+// banks, are not found until the table is regenerated; see go generate.
+func BankInfo(nrb string) (Bank, bool) {
+	return lookupBank(banks(), nrb)
+}
+
+func lookupBank(table map[string]Bank, nrb string) (Bank, bool) {
+	nrb = NormalizeNRB(nrb)
+	if len(nrb) < 10 || !isDigitsOnly(nrb[:10]) {
+		return Bank{}, false
+	}
+	sortCode := nrb[2:10]
+	if b, ok := table[sortCode]; ok {
+		return b, true
+	}
+	b, ok := table[sortCode[:4]]
+	return b, ok
+}
+
+// santanderSortCodePrefix starts the sort codes of Santander Bank Polska.
+const santanderSortCodePrefix = "109"
+
+func isSantanderAccount(nrb string) bool {
+	return len(nrb) >= 5 && nrb[2:5] == santanderSortCodePrefix
+}
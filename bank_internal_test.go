@@ -0,0 +1,43 @@
+package sanpltxt
+
+import (
+	"testing"
+
+	"github.com/zeebo/assert"
+)
+
+// The bundled table has no branches, so branch lookup is tested on the
+// output of internal/genbanks for its test sample.
+const genbanksTable = `# Code generated by internal/genbanks; DO NOT EDIT.
+# sort_code;name;branch;bic
+1090;Santander Bank Polska;;WBKPPLPP
+10901043;Santander Bank Polska;1 Oddział w Warszawie;WBKPPLPP
+10901056;Santander Bank Polska;2 Oddział w Poznaniu;WBKPPLPP
+8999;Bank Spółdzielczy w Przykładowie;;GBWCPLPP
+89990001;Bank Spółdzielczy w Przykładowie;Centrala, Oddział;GBWCPLPP
+`
+
+func TestLookupBank_Branch(t *testing.T) {
+	table, err := parseBanks(genbanksTable)
+	assert.NoError(t, err)
+
+	b, ok := lookupBank(table, "PL51 1090 1043 0000 0001 0011 1111")
+	assert.True(t, ok)
+	assert.Equal(t, b, Bank{SortCode: "10901043", Name: "Santander Bank Polska", Branch: "1 Oddział w Warszawie", BIC: "WBKPPLPP"})
+
+	// A branch missing from the table falls back to the bank.
+	b, ok = lookupBank(table, "27109099990000000100111111")
+	assert.True(t, ok)
+	assert.Equal(t, b.SortCode, "1090")
+	assert.Equal(t, b.Branch, "")
+
+	b, ok = lookupBank(table, "00899900010000000000000000")
+	assert.True(t, ok)
+	assert.Equal(t, b.Branch, "Centrala, Oddział")
+
+	_, ok = lookupBank(table, "50102055581111103350100016")
+	assert.False(t, ok)
+
+	_, err = parseBanks("1090;Santander Bank Polska;WBKPPLPP\n")
+	assert.Error(t, err)
+}
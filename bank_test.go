@@ -0,0 +1,30 @@
+package sanpltxt_test
+
+import (
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func TestBankInfo(t *testing.T) {
+	b, ok := sanpltxt.BankInfo("51109010430000000100111111")
+	assert.True(t, ok)
+	assert.Equal(t, b.Name, "Santander Bank Polska")
+	assert.Equal(t, b.BIC, "WBKPPLPP")
+
+	b, ok = sanpltxt.BankInfo("PL50 1020 5558 1111 1033 5010 0016")
+	assert.True(t, ok)
+	assert.Equal(t, b.Name, "PKO Bank Polski")
+
+	_, ok = sanpltxt.BankInfo("51999910430000000100111111")
+	assert.False(t, ok)
+	_, ok = sanpltxt.BankInfo("123")
+	assert.False(t, ok)
+
+	p := &sanpltxt.Payroll{CreditAccount: "50102055581111103350100016"}
+	b, ok = p.RecipientBank()
+	assert.True(t, ok)
+	assert.Equal(t, b.BIC, "BPKOPLPW")
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/amwolff/sanpltxt"
+	"github.com/amwolff/sanpltxt/csvimport"
+)
+
+func runConvert(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		format      = fs.String("format", "", "input `format`: csv, json or yaml (default from the file extension)")
+		comma       = fs.String("comma", "", "CSV field `separator`, TAB for a tab (default ; or , detected from the header)")
+		mappingFile = fs.String("mapping", "", "JSON or YAML `file` mapping field names to input columns")
+		defaultType = fs.String("type", "", "transfer `type` of rows without a Type value: standard, zus, tax, payroll or split")
+		out         = fs.String("o", "", "output `file`, - for stdout (default: input name with a .txt extension)")
+		encodeUTF8  = fs.Bool("utf8", false, "write UTF-8 instead of Windows-1250")
+		sanitize    = fs.Bool("sanitize", false, "rewrite free-text fields to fit their allowed characters and lengths")
+		reportFile  = fs.String("report", "", "write a JSON report to `file`, - for stdout")
+		flagMapping = mapping{}
+	)
+	fs.Var(flagMapping, "map", "comma-separated `Field=Column` pairs; overrides -mapping; may be repeated")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: sanpltxt convert [flags] input
+
+Convert CSV, JSON or YAML rows to a Santander import file. Use - as input to
+read from stdin. CSV input needs a header row and may be UTF-8 or
+Windows-1250 as saved by Excel; JSON input is an array of objects and YAML
+input a sequence of mappings.
+
+Each row describes one transfer. Values are read from columns named after the
+fields below unless mapped to other columns with -map or -mapping:
+
+  %s
+
+Type is standard, zus, tax, payroll, split or a type number 1-6; TaxOffice
+selects type 3 for tax transfers. Split payments take GrossAmount, or Amount
+if it is empty. Amounts may use Polish formatting such as "1 234,56". Dates
+are DD-MM-YYYY, YYYY-MM-DD or DD.MM.YYYY. Mode is internal, elixir, sorbnet,
+express or a mode number and defaults to elixir.
+
+Flags:
+`, fieldList())
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "sanpltxt convert: expected one input file")
+		fs.Usage()
+		return exitUsage
+	}
+	input := fs.Arg(0)
+
+	usageErr := func(format string, a ...any) int {
+		fmt.Fprintf(stderr, "sanpltxt convert: "+format+"\n", a...)
+		return exitUsage
+	}
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(input)) {
+		case ".csv":
+			*format = "csv"
+		case ".json":
+			*format = "json"
+		case ".yaml", ".yml":
+			*format = "yaml"
+		default:
+			return usageErr("cannot tell the format of %q, use -format", input)
+		}
+	}
+	var sep rune
+	switch {
+	case *comma == "":
+	case *comma == "TAB":
+		sep = '\t'
+	case utf8.RuneCountInString(*comma) == 1:
+		sep, _ = utf8.DecodeRuneInString(*comma)
+	default:
+		return usageErr("separator must be a single character, got %q", *comma)
+	}
+	if *out == "" {
+		if input == "-" {
+			*out = "-"
+		} else {
+			*out = strings.TrimSuffix(input, filepath.Ext(input)) + ".txt"
+		}
+	}
+	if *out == input && input != "-" {
+		return usageErr("output would overwrite %q, use -o", input)
+	}
+	if *out == "-" && *reportFile == "-" {
+		return usageErr("output and report cannot both go to stdout")
+	}
+
+	m := mapping{}
+	if *mappingFile != "" {
+		if err := m.load(*mappingFile); err != nil {
+			return usageErr("%v", err)
+		}
+	}
+	for f, c := range flagMapping {
+		m[f] = c
+	}
+
+	rep := &report{Input: input}
+	finish := func(code int) int {
+		if *reportFile == "" {
+			return code
+		}
+		if err := rep.write(*reportFile, stdout); err != nil {
+			fmt.Fprintf(stderr, "sanpltxt convert: writing report: %v\n", err)
+			if code == exitOK {
+				code = exitIO
+			}
+		}
+		return code
+	}
+	fail := func(code int, errs ...reportError) int {
+		rep.Errors = append(rep.Errors, errs...)
+		printErrors(stderr, errs)
+		return finish(code)
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if input == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return fail(exitIO, reportError{Message: err.Error()})
+	}
+
+	var (
+		records []record
+		errs    []reportError
+	)
+	if *format == "csv" {
+		records, errs, err = convertCSV(data, sep, m, *defaultType)
+	} else {
+		var rows []row
+		if rows, err = readRows(data, *format); err == nil {
+			records, errs = convertRows(rows, m, *defaultType)
+		}
+	}
+	if err != nil {
+		return fail(exitInvalid, reportError{Message: err.Error()})
+	}
+
+	opts := &sanpltxt.PackageOptions{EncodeUTF8: *encodeUTF8, Sanitize: *sanitize}
+	pkg := buildPackage(records, opts)
+	if err := pkg.Validate(); err != nil {
+		errs = append(errs, validationReport(err, records, m)...)
+	}
+	rep.Transfers = len(pkg.Transfers())
+	if len(errs) > 0 {
+		code := fail(exitInvalid, errs...)
+		fmt.Fprintf(stderr, "sanpltxt convert: %d errors, nothing written\n", len(errs))
+		return code
+	}
+
+	opts.OnSanitize = func(c sanpltxt.SanitizeChange) {
+		rep.Sanitized = append(rep.Sanitized, reportChange{
+			Row:       records[c.TransferIndex].row,
+			Field:     c.Field,
+			Original:  c.Original,
+			Sanitized: c.Sanitized,
+		})
+	}
+	b, err := sanpltxt.NewPackage(pkg.Type(), pkg.Transfers(), opts).MarshalBytes()
+	if err != nil {
+		return fail(exitInvalid, reportError{Message: err.Error()})
+	}
+
+	if *out == "-" {
+		_, err = stdout.Write(b)
+	} else {
+		err = os.WriteFile(*out, b, 0o600)
+	}
+	if err != nil {
+		return fail(exitIO, reportError{Message: err.Error()})
+	}
+
+	rep.OK = true
+	if *out != "-" {
+		rep.Output = *out
+		fmt.Fprintf(stderr, "sanpltxt convert: wrote %d transfers to %s\n", rep.Transfers, *out)
+	}
+	return finish(exitOK)
+}
+
+// fieldList returns the field names for the usage text.
+func fieldList() string {
+	names := make([]string, 0, len(csvimport.Fields()))
+	for _, f := range csvimport.Fields() {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+// importFile is a decoded Santander import file.
+type importFile struct {
+	name      string
+	utf8      bool
+	lines     []string // decoded lines without line endings
+	typ       int
+	transfers []sanpltxt.Transfer
+	lineOf    []int         // line number of every transfer
+	errs      []reportError // malformed lines
+}
+
+// readImportFile reads and decodes an import file, or stdin if name is "-".
+// The file is UTF-8 if it is valid UTF-8 and Windows-1250 otherwise.
+// Malformed transfer lines are collected in errs; an error is returned only
+// if the file cannot be read or its header is invalid.
+func readImportFile(name string, stdin io.Reader) (*importFile, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if name == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &importFile{name: name, utf8: utf8.Valid(data)}
+	s := string(data)
+	if !f.utf8 {
+		if s, err = sanpltxt.FromWindows1250(data); err != nil {
+			return nil, err
+		}
+	}
+	s = strings.TrimPrefix(s, "\ufeff")
+	for line := range strings.Lines(s) {
+		f.lines = append(f.lines, strings.TrimRight(line, "\r\n"))
+	}
+
+	dec := sanpltxt.NewDecoder(strings.NewReader(s), &sanpltxt.PackageOptions{EncodeUTF8: true})
+	if f.typ, err = dec.Type(); err != nil {
+		return nil, err
+	}
+	for t, err := range dec.All() {
+		if err == nil {
+			f.transfers = append(f.transfers, t)
+			f.lineOf = append(f.lineOf, dec.Line())
+			continue
+		}
+		var perr *sanpltxt.ParseError
+		if !errors.As(err, &perr) {
+			return nil, err
+		}
+		e := reportError{Line: perr.Line, Message: perr.Err.Error()}
+		if perr.Field > 0 {
+			e.Field = f.fieldName(perr.Line, perr.Field)
+			e.Col = f.column(perr.Line, perr.Field)
+		}
+		f.errs = append(f.errs, e)
+	}
+	return f, nil
+}
+
+// validate checks the transfers against the library's rules and returns the
+// malformed lines and validation errors ordered by line.
+func (f *importFile) validate() []reportError {
+	errs := slices.Clone(f.errs)
+
+	err := sanpltxt.NewPackage(f.typ, f.transfers, nil).Validate()
+	var verrs sanpltxt.ValidationErrors
+	if errors.As(err, &verrs) {
+		for _, v := range verrs {
+			plain := *v
+			plain.TransferIndex = -1 // the report points at the line instead
+			e := reportError{Field: v.Field, Rule: string(v.Rule), Value: v.Value, Message: plain.Error()}
+			if v.TransferIndex >= 0 {
+				e.Line = f.lineOf[v.TransferIndex]
+				if pos := fieldPosition(v.TransferType, v.Field); pos > 0 {
+					e.Col = f.column(e.Line, pos)
+				}
+			} else {
+				e.Line = 1 // package-level errors concern the header
+			}
+			errs = append(errs, e)
+		}
+	} else if err != nil {
+		errs = append(errs, reportError{Message: err.Error()})
+	}
+
+	slices.SortStableFunc(errs, func(a, b reportError) int { return a.Line - b.Line })
+	return errs
+}
+
+// position formats the location of e in the file for messages, such as
+// "file.txt:3:45".
+func (f *importFile) position(e reportError) string {
+	s := f.name
+	if e.Line > 0 {
+		s += ":" + strconv.Itoa(e.Line)
+		if e.Col > 0 {
+			s += ":" + strconv.Itoa(e.Col)
+		}
+	}
+	return s
+}
+
+// column returns the 1-based character column where field pos starts on the
+// given line.
+func (f *importFile) column(line, pos int) int {
+	if line < 1 || line > len(f.lines) {
+		return 0
+	}
+	col := 1
+	for i, field := range strings.Split(f.lines[line-1], "|") {
+		if i == pos-1 {
+			return col
+		}
+		col += utf8.RuneCountInString(field) + 1
+	}
+	return col // missing field: the end of the line
+}
+
+// fieldName returns the name of field pos on the given line.
+func (f *importFile) fieldName(line, pos int) string {
+	if line < 1 || line > len(f.lines) {
+		return ""
+	}
+	typ, _, _ := strings.Cut(f.lines[line-1], "|")
+	n, _ := strconv.Atoi(typ)
+	if names := lineFields[n]; pos <= len(names) {
+		return names[pos-1]
+	}
+	return ""
+}
+
+// lineFields lists the fields of each transfer type in file order.
+var lineFields = map[int][]string{
+	1: {"Type", "DebitAccount", "CreditAccount", "RecipientName", "Address", "Amount", "Mode", "Title", "Date", "NIP"},
+	2: {"Type", "DebitAccount", "CreditAccount", "RecipientName", "Address", "Amount", "Mode", "Title", "Date"},
+	3: taxFields,
+	4: taxFields,
+	5: {"Type", "DebitAccount", "CreditAccount", "RecipientName", "Address", "Amount", "Mode", "Title", "Date"},
+	6: {"Type", "DebitAccount", "CreditAccount", "RecipientName", "Address", "GrossAmount", "Mode", "Title", "Date"},
+}
+
+var taxFields = []string{
+	"Type", "DebitAccount", "CreditAccount", "RecipientName", "Address", "Amount", "Date",
+	"PayerName", "IdentifierType", "Identifier", "Year", "PeriodType", "PeriodNumber", "FormSymbol", "ObligationID",
+}
+
+// fieldPosition returns the 1-based position of a struct field in lines of
+// the given transfer type, or 0 if it has none. Split payment fields encoded
+// in the title are at the title's position.
+func fieldPosition(typ int, field string) int {
+	if typ == 6 {
+		switch field {
+		case "VATAmount", "RecipientNIP", "InvoiceNumber", "FreeText":
+			field = "Title"
+		}
+	}
+	return slices.Index(lineFields[typ], field) + 1
+}
+
+// charsetName returns the name of the file's character set.
+func (f *importFile) charsetName() string {
+	if f.utf8 {
+		return "UTF-8"
+	}
+	return "Windows-1250"
+}
+
+func packageTypeName(typ int) string {
+	switch typ {
+	case 1:
+		return "1 (regular)"
+	case 2:
+		return "2 (payroll)"
+	}
+	return fmt.Sprint(typ)
+}
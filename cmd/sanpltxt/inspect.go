@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func runInspect(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `Usage: sanpltxt inspect file
+
+Print the transfers of an existing import file as a table, with split payment
+titles and tax fields decoded, followed by the package totals. Use - as file
+to read from stdin. The file may be UTF-8 or Windows-1250. Malformed lines are
+reported on stderr.
+`)
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "sanpltxt inspect: expected one file")
+		fs.Usage()
+		return exitUsage
+	}
+	name := fs.Arg(0)
+
+	f, err := readImportFile(name, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "sanpltxt inspect: %v\n", err)
+		var perr *sanpltxt.ParseError
+		if errors.As(err, &perr) {
+			return exitInvalid
+		}
+		return exitIO
+	}
+
+	fmt.Fprintf(stdout, "File:     %s\nCharset:  %s\nPackage:  %s\n\n", name, f.charsetName(), packageTypeName(f.typ))
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tTYPE\tDEBIT ACCOUNT\tCREDIT ACCOUNT\tRECIPIENT\tAMOUNT\tMODE\tDATE\tTITLE")
+	for i, t := range f.transfers {
+		r := describe(t)
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", f.lineOf[i], r.typ, r.debit, r.credit, r.recipient, r.amount, r.mode, r.date, r.title)
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintf(stderr, "sanpltxt inspect: %v\n", err)
+		return exitIO
+	}
+
+	fmt.Fprintln(stdout)
+	if err := sanpltxt.NewPackage(f.typ, f.transfers, nil).Summary().WriteText(stdout); err != nil {
+		fmt.Fprintf(stderr, "sanpltxt inspect: %v\n", err)
+		return exitIO
+	}
+
+	for _, e := range f.errs {
+		fmt.Fprintf(stderr, "%s: %s\n", f.position(e), e.Message)
+	}
+	if len(f.errs) > 0 {
+		return exitInvalid
+	}
+	return exitOK
+}
+
+// transferRow is a transfer formatted for the inspect table.
+type transferRow struct {
+	typ, debit, credit, recipient, amount, mode, date, title string
+}
+
+func describe(t sanpltxt.Transfer) transferRow {
+	switch t := t.(type) {
+	case *sanpltxt.Standard:
+		title := t.Title
+		if t.NIP != "" {
+			title += " (NIP " + t.NIP + ")"
+		}
+		return transferRow{"standard", t.DebitAccount, t.CreditAccount, t.RecipientName, t.Amount.String(), modeName(t.Mode), dateName(t.Date), title}
+	case *sanpltxt.ZUS:
+		return transferRow{"ZUS", t.DebitAccount, t.CreditAccount, t.RecipientName, t.Amount.String(), modeName(sanpltxt.ModeElixir), dateName(t.Date), t.Title}
+	case *sanpltxt.Tax:
+		typ := "tax"
+		if t.TaxOffice {
+			typ = "tax office"
+		}
+		title := []string{
+			"payer " + t.PayerName,
+			"ID " + string(t.IdentifierType) + " " + t.Identifier,
+			"period " + t.Year + string(t.PeriodType) + t.PeriodNumber,
+			"form " + t.FormSymbol,
+		}
+		if t.ObligationID != "" {
+			title = append(title, "obligation "+t.ObligationID)
+		}
+		return transferRow{typ, t.DebitAccount, t.CreditAccount, t.RecipientName, t.Amount.String(), modeName(sanpltxt.ModeElixir), dateName(t.Date), strings.Join(title, ", ")}
+	case *sanpltxt.Payroll:
+		return transferRow{"payroll", t.DebitAccount, t.CreditAccount, t.RecipientName, t.Amount.String(), modeName(t.Mode), dateName(t.Date), t.Title}
+	case *sanpltxt.SplitPayment:
+		title := []string{"VAT " + t.VATAmount.String(), "NIP " + t.RecipientNIP, "invoice " + t.InvoiceNumber}
+		if t.FreeText != "" {
+			title = append(title, t.FreeText)
+		}
+		return transferRow{"split payment", t.DebitAccount, t.CreditAccount, t.RecipientName, t.GrossAmount.String(), modeName(t.Mode), dateName(t.Date), strings.Join(title, ", ")}
+	}
+	return transferRow{typ: fmt.Sprintf("%T", t)}
+}
+
+func modeName(m sanpltxt.TransferMode) string {
+	if name := m.Name(); name != "" {
+		return name
+	}
+	return m.String()
+}
+
+func dateName(d *time.Time) string {
+	if d == nil {
+		return "immediate"
+	}
+	return d.Format(time.DateOnly)
+}
@@ -0,0 +1,73 @@
+// Command sanpltxt converts batches of transfers described in CSV, JSON or
+// YAML into Santander import files, checks and inspects existing ones and
+// serves validation and rendering over HTTP.
+//
+// Usage:
+//
+//	sanpltxt convert [flags] input
+//	sanpltxt validate [flags] file
+//	sanpltxt inspect file
+//	sanpltxt serve [flags]
+//
+// Run a command with -h for its flags.
+//
+// Exit codes:
+//
+//	0  success
+//	1  the input holds invalid transfers; convert writes nothing
+//	2  invalid command line
+//	3  input or output could not be read or written, or serve could not listen
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+	exitIO      = 3
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "convert":
+		return runConvert(args[1:], stdin, stdout, stderr)
+	case "validate":
+		return runValidate(args[1:], stdin, stdout, stderr)
+	case "inspect":
+		return runInspect(args[1:], stdin, stdout, stderr)
+	case "serve":
+		return runServe(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "sanpltxt: unknown command %q\n", args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: sanpltxt <command> [flags] [arguments]
+
+Commands:
+  convert   convert CSV, JSON or YAML rows to a Santander import file
+  validate  check an existing import file
+  inspect   print the transfers and totals of an import file
+  serve     validate and render JSON packages over HTTP
+
+Run "sanpltxt <command> -h" for the flags of a command.
+`)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	return p
+}
+
+func TestConvert_CSV(t *testing.T) {
+	in := writeFile(t, "batch.csv", `rodzaj;konto;odbiorca_konto;odbiorca;adres;kwota;tytul;data
+payroll;51109010430000000100111111;50102055581111103350100016;Jan Nowak;Poznań ul. Swojska 17 06-123;1 000,12;Wynagrodzenie za miesiąc;2020-09-01
+`)
+	mapping := writeFile(t, "mapping.yaml", `
+Type: rodzaj
+DebitAccount: konto
+CreditAccount: odbiorca_konto
+RecipientName: odbiorca
+Address: adres
+Amount: kwota
+Title: tytul
+Date: data
+`)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"convert", "-comma", ";", "-mapping", mapping, in}, nil, &stdout, &stderr)
+	assert.Equal(t, code, exitOK)
+
+	b, err := os.ReadFile(strings.TrimSuffix(in, ".csv") + ".txt")
+	assert.NoError(t, err)
+	pkg, err := sanpltxt.ParsePackageBytes(b, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.Type(), 2)
+
+	date := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	assert.DeepEqual(t, pkg.Transfers(), []sanpltxt.Transfer{
+		&sanpltxt.Payroll{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jan Nowak",
+			Address:       "Poznań ul. Swojska 17 06-123",
+			Amount:        100012,
+			Mode:          sanpltxt.ModeElixir,
+			Title:         "Wynagrodzenie za miesiąc",
+			Date:          &date,
+		},
+	})
+}
+
+func TestConvert_MalformedCSV(t *testing.T) {
+	in := writeFile(t, "bad.csv", "Type;Amount\nstandard;x\"y\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"convert", in}, nil, &stdout, &stderr)
+	assert.Equal(t, code, exitInvalid)
+	assert.True(t, strings.Contains(stderr.String(), "row 1, line 2: "))
+	_, err := os.Stat(strings.TrimSuffix(in, ".csv") + ".txt")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConvert_JSONErrorReport(t *testing.T) {
+	in := writeFile(t, "batch.json", `[
+	{"type": "standard", "DebitAccount": "51109010430000000100111111", "CreditAccount": "50102055581111103350100016",
+	 "RecipientName": "Jerzy Kowalski", "Address": "Warszawa", "Amount": 123.12, "Title": "Faktura 1"},
+	{"type": "split", "DebitAccount": "51109010430000000100111111", "CreditAccount": "50102055581111103350100017",
+	 "RecipientName": "Jan Nowak", "Amount": "123,50", "VATAmount": "23,09", "RecipientNIP": "8960005673", "InvoiceNumber": "5/2018"},
+	{"type": "transfer"}
+]`)
+	out := filepath.Join(t.TempDir(), "out.txt")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"convert", "-o", out, "-report", "-", in}, nil, &stdout, &stderr)
+	assert.Equal(t, code, exitInvalid)
+	_, err := os.Stat(out)
+	assert.True(t, os.IsNotExist(err))
+
+	var rep report
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &rep))
+	assert.False(t, rep.OK)
+	assert.Equal(t, len(rep.Errors), 2)
+	assert.Equal(t, rep.Errors[0].Row, 3)
+	assert.Equal(t, rep.Errors[0].Field, "Type")
+	assert.Equal(t, rep.Errors[1].Row, 2)
+	assert.Equal(t, rep.Errors[1].Field, "CreditAccount")
+	assert.Equal(t, rep.Errors[1].Rule, string(sanpltxt.RuleChecksum))
+	assert.True(t, strings.Contains(stderr.String(), `row 2, column "CreditAccount": credit account`))
+}
+
+func TestConvert_YAMLStdin(t *testing.T) {
+	in := `
+- Type: zus
+  DebitAccount: "51109010430000000100111111"
+  CreditAccount: "20600000020260111122223333"
+  RecipientName: ZUS
+  Address: Warszawa ul. Szamocka 3,5 01748
+  Amount: 319.94
+  Title: Skladka ZUS
+`
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"convert", "-format", "yaml", "-utf8", "-"}, strings.NewReader(in), &stdout, &stderr)
+	assert.Equal(t, code, exitOK)
+	assert.Equal(t, stdout.String(), "4120414|1\n2|51109010430000000100111111|20600000020260111122223333|ZUS|Warszawa ul. Szamocka 3,5 01748|319,94|1|Skladka ZUS||\n")
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, run(nil, nil, &stdout, &stderr), exitUsage)
+	assert.Equal(t, run([]string{"frobnicate"}, nil, &stdout, &stderr), exitUsage)
+	assert.Equal(t, run([]string{"convert", "batch.xls"}, nil, &stdout, &stderr), exitUsage)
+	assert.Equal(t, run([]string{"convert", "-map", "Colour=kolor", "batch.csv"}, nil, &stdout, &stderr), exitUsage)
+	assert.Equal(t, run([]string{"convert", "missing.csv"}, nil, &stdout, &stderr), exitIO)
+	assert.Equal(t, run([]string{"serve", "extra"}, nil, &stdout, &stderr), exitUsage)
+	assert.Equal(t, run([]string{"serve", "-addr", "localhost:-1"}, nil, &stdout, &stderr), exitIO)
+}
+
+const badFile = "4120414|1\r\n" +
+	"1|51109010430000000100111111|50102055581111103350100016|Jerzy Kowalski|Warszawa|0|1|Faktura|01-09-2020||\r\n" +
+	"6|51109010430000000100111111|88102055581111103350100011|Jan Nowak||123,50|1|/VAT/223,09/IDC/8960005673/INV/5/2018|30-09-2020|\r\n" +
+	"1|51109010430000000100111111|50102055581111103350100016|Poznań|Poznań|12.50|1|Pensja|||\r\n"
+
+func TestValidate(t *testing.T) {
+	b, err := sanpltxt.ToWindows1250(badFile)
+	assert.NoError(t, err)
+	in := writeFile(t, "bad.txt", string(b))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"validate", "-report", "-", in}, nil, &stdout, &stderr)
+	assert.Equal(t, code, exitInvalid)
+	assert.True(t, strings.HasPrefix(stderr.String(), in+`:2:81: amount must be positive, got 0
+`+in+`:3:77: VAT amount 223,09 must not exceed gross amount 123,50
+`+in+`:4:71: invalid amount "12.50"
+`))
+
+	var rep report
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &rep))
+	assert.Equal(t, len(rep.Errors), 3)
+	assert.Equal(t, rep.Errors[2].Field, "Amount")
+	assert.Equal(t, rep.Errors[2].Col, 71)
+
+	stderr.Reset()
+	good := "4120414|1\n" + strings.SplitAfter(badFile, "\r\n")[2]
+	good = strings.Replace(good, "223,09", "23,09", 1)
+	code = run([]string{"validate", "-"}, strings.NewReader(good), &stdout, &stderr)
+	assert.Equal(t, code, exitOK)
+	assert.Equal(t, stderr.String(), "-: 1 transfers OK (UTF-8, package type 1 (regular))\n")
+}
+
+func TestInspect(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"inspect", "-"}, strings.NewReader(badFile), &stdout, &stderr)
+	assert.Equal(t, code, exitInvalid)
+	assert.Equal(t, stderr.String(), `-:4:71: invalid amount "12.50"`+"\n")
+
+	out := stdout.String()
+	for _, want := range []string{
+		"Charset:  UTF-8",
+		"LINE  TYPE",
+		"split payment  51109010430000000100111111  88102055581111103350100011  Jan Nowak       123,50  Elixir  2020-09-30  VAT 223,09, NIP 8960005673, invoice 5/2018",
+		"  6 split payment             1  123,50",
+	} {
+		assert.True(t, strings.Contains(out, want))
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/amwolff/sanpltxt/csvimport"
+)
+
+// mapping maps fields to input column names. Fields without an entry are read
+// from the column of the same name. Column names are compared
+// case-insensitively.
+type mapping map[csvimport.Field]string
+
+func (m mapping) column(f csvimport.Field) string {
+	if c, ok := m[f]; ok {
+		return c
+	}
+	return string(f)
+}
+
+// csv returns the mapping in the form csvimport expects.
+func (m mapping) csv() csvimport.Mapping {
+	cm := make(csvimport.Mapping, len(m))
+	for f, c := range m {
+		cm[c] = f
+	}
+	return cm
+}
+
+// set records that field is read from column.
+func (m mapping) set(field, column string) error {
+	f, ok := csvimport.ParseField(field)
+	if !ok {
+		return fmt.Errorf("unknown field %q", field)
+	}
+	m[f] = column
+	return nil
+}
+
+// String implements flag.Value.
+func (m mapping) String() string {
+	var pairs []string
+	for _, f := range csvimport.Fields() {
+		if c, ok := m[f]; ok {
+			pairs = append(pairs, string(f)+"="+c)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set implements flag.Value. It accepts comma-separated Field=Column pairs.
+func (m mapping) Set(s string) error {
+	for pair := range strings.SplitSeq(s, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("mapping %q must be in the form Field=Column", pair)
+		}
+		if err := m.set(strings.TrimSpace(field), strings.TrimSpace(column)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load reads a mapping from a JSON or YAML file holding an object of
+// field-to-column pairs.
+func (m mapping) load(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	var pairs map[string]string
+	if err := yaml.Unmarshal(data, &pairs); err != nil { // YAML is a superset of JSON
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	for field, column := range pairs {
+		if err := m.set(field, column); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// report is the machine-readable result of a command, written as JSON with
+// the -report flag.
+type report struct {
+	OK        bool           `json:"ok"`
+	Input     string         `json:"input,omitempty"`
+	Output    string         `json:"output,omitempty"`
+	Transfers int            `json:"transfers"`
+	Errors    []reportError  `json:"errors"`
+	Sanitized []reportChange `json:"sanitized,omitempty"`
+}
+
+// reportError is a single problem found in the input. Row is the 1-based
+// record number, not counting a CSV header; Line is the line in the input
+// file where known and Col the character column in an import file. Rule is
+// one of the sanpltxt.Rule values.
+type reportError struct {
+	Row     int    `json:"row,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Col     int    `json:"col,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Column  string `json:"column,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e reportError) String() string {
+	var pos []string
+	if e.Row > 0 {
+		pos = append(pos, "row "+strconv.Itoa(e.Row))
+	}
+	if e.Line > 0 {
+		pos = append(pos, "line "+strconv.Itoa(e.Line))
+	}
+	if e.Column != "" {
+		pos = append(pos, "column "+strconv.Quote(e.Column))
+	}
+	if len(pos) == 0 {
+		return e.Message
+	}
+	return strings.Join(pos, ", ") + ": " + e.Message
+}
+
+// reportChange is a field value rewritten by -sanitize.
+type reportChange struct {
+	Row       int    `json:"row"`
+	Field     string `json:"field"`
+	Original  string `json:"original"`
+	Sanitized string `json:"sanitized"`
+}
+
+// write writes the report as JSON to name, or to stdout if name is "-".
+func (r *report) write(name string, stdout io.Writer) error {
+	if r.Errors == nil {
+		r.Errors = []reportError{}
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if name == "-" {
+		_, err = stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(name, b, 0o600)
+}
+
+func printErrors(w io.Writer, errs []reportError) {
+	for _, e := range errs {
+		fmt.Fprintln(w, e)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// row is one input record with its values keyed by lower-cased column name.
+type row struct {
+	line   int // line in the input, 0 if unknown
+	values map[string]string
+}
+
+// readRows reads the records of a JSON or YAML document. JSON input is an
+// array of objects and YAML input a sequence of mappings. CSV input is read by
+// csvimport instead.
+func readRows(data []byte, format string) ([]row, error) {
+	switch format {
+	case "json":
+		return readJSON(data)
+	case "yaml":
+		return readYAML(data)
+	}
+	return nil, fmt.Errorf("unsupported input format %q", format)
+}
+
+func readJSON(data []byte) ([]row, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var records []map[string]any
+	if err := dec.Decode(&records); err != nil {
+		return nil, err
+	}
+
+	rows := make([]row, len(records))
+	for i, rec := range records {
+		rows[i].values = make(map[string]string, len(rec))
+		for k, v := range rec {
+			var s string
+			switch v := v.(type) {
+			case nil:
+			case string:
+				s = v
+			case json.Number:
+				s = v.String()
+			case bool:
+				s = fmt.Sprint(v)
+			default:
+				return nil, fmt.Errorf("record %d: value of %q must be a string, number or boolean", i+1, k)
+			}
+			rows[i].values[strings.ToLower(k)] = strings.TrimSpace(s)
+		}
+	}
+	return rows, nil
+}
+
+func readYAML(data []byte) ([]row, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	seq := doc.Content[0]
+	if seq.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("line %d: document must be a sequence of records", seq.Line)
+	}
+
+	rows := make([]row, len(seq.Content))
+	for i, rec := range seq.Content {
+		if rec.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("line %d: record must be a mapping", rec.Line)
+		}
+		rows[i] = row{line: rec.Line, values: make(map[string]string, len(rec.Content)/2)}
+		for j := 0; j+1 < len(rec.Content); j += 2 {
+			k, v := rec.Content[j], rec.Content[j+1]
+			if v.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: value of %q must be a scalar", v.Line, k.Value)
+			}
+			s := v.Value
+			if v.Tag == "!!null" {
+				s = ""
+			}
+			rows[i].values[strings.ToLower(k.Value)] = strings.TrimSpace(s)
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/amwolff/sanpltxt/server"
+)
+
+func runServe(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		addr    = fs.String("addr", "localhost:8080", "listen `address`")
+		maxBody = fs.Int64("max-body", server.DefaultMaxBodySize, "request body limit in `bytes`")
+	)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `Usage: sanpltxt serve [flags]
+
+Serve package validation and rendering over HTTP:
+
+  POST /validate  check a JSON package and report every problem found
+  POST /render    return the import file, encoded in Windows-1250
+  GET  /schema    return the JSON Schema of the request body
+
+Both POST endpoints accept ?sanitize=true; /render also accepts ?filename=.
+The server stops on SIGINT or SIGTERM after finishing pending requests.
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(stderr, "sanpltxt serve: unexpected arguments")
+		fs.Usage()
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(stderr, "sanpltxt serve: %v\n", err)
+		return exitIO
+	}
+	logger := log.New(stderr, "sanpltxt serve: ", log.LstdFlags)
+	srv := &http.Server{
+		Handler:           server.New(&server.Options{MaxBodySize: *maxBody, ErrorLog: logger}),
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          logger,
+	}
+	logger.Printf("listening on http://%s", ln.Addr())
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		err = srv.Shutdown(shutdownCtx)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Print(err)
+		return exitIO
+	}
+	return exitOK
+}
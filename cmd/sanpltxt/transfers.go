@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/amwolff/sanpltxt"
+	"github.com/amwolff/sanpltxt/csvimport"
+)
+
+// record is a converted input record.
+type record struct {
+	row, line int // 1-based record number and input line, 0 if unknown
+	transfer  sanpltxt.Transfer
+}
+
+// convertCSV converts CSV input with csvimport. comma may be 0 to detect the
+// separator. It returns the converted records and the errors of the rows that
+// could not be converted, or an error if the header is unusable.
+func convertCSV(data []byte, comma rune, m mapping, defaultType string) ([]record, []reportError, error) {
+	r := csvimport.NewReader(bytes.NewReader(data), &csvimport.Options{
+		Mapping:     m.csv(),
+		Comma:       comma,
+		DefaultType: defaultType,
+	})
+	if _, err := r.Header(); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		records []record
+		errs    []reportError
+	)
+	for t, err := range r.All() {
+		if err != nil {
+			errs = append(errs, rowReport(err, m)...)
+			continue
+		}
+		records = append(records, record{row: r.Row(), line: r.Line(), transfer: t})
+	}
+	return records, errs, nil
+}
+
+// convertRows converts JSON or YAML records with csvimport.Convert.
+func convertRows(rows []row, m mapping, defaultType string) ([]record, []reportError) {
+	var (
+		records []record
+		errs    []reportError
+	)
+	for i, rw := range rows {
+		rec := make(csvimport.Record)
+		for _, f := range csvimport.Fields() {
+			if v, ok := rw.values[strings.ToLower(m.column(f))]; ok {
+				rec[f] = v
+			}
+		}
+		t, err := csvimport.Convert(rec, defaultType)
+		if err != nil {
+			var rerr *csvimport.RowError
+			if errors.As(err, &rerr) {
+				rerr.Row, rerr.Line = i+1, rw.line
+			}
+			errs = append(errs, rowReport(err, m)...)
+			continue
+		}
+		records = append(records, record{row: i + 1, line: rw.line, transfer: t})
+	}
+	return records, errs
+}
+
+// rowReport converts a csvimport row error to report entries.
+func rowReport(err error, m mapping) []reportError {
+	var rerr *csvimport.RowError
+	if !errors.As(err, &rerr) {
+		return []reportError{{Message: err.Error()}}
+	}
+	errs := make([]reportError, len(rerr.Errors))
+	for i, ferr := range rerr.Errors {
+		errs[i] = reportError{
+			Row:     rerr.Row,
+			Line:    rerr.Line,
+			Field:   string(ferr.Field),
+			Column:  ferr.Column,
+			Rule:    string(ferr.Rule),
+			Value:   ferr.Value,
+			Message: ferr.Err.Error(),
+		}
+		if errs[i].Column == "" && ferr.Field != "" {
+			errs[i].Column = m.column(ferr.Field)
+		}
+	}
+	return errs
+}
+
+// buildPackage puts the converted transfers into a package. The package is a
+// payroll package if it holds payroll transfers only.
+func buildPackage(records []record, opts *sanpltxt.PackageOptions) *sanpltxt.Package {
+	transfers := make([]sanpltxt.Transfer, len(records))
+	payroll := len(records) > 0
+	for i, r := range records {
+		transfers[i] = r.transfer
+		if _, ok := r.transfer.(*sanpltxt.Payroll); !ok {
+			payroll = false
+		}
+	}
+	typ := 1
+	if payroll {
+		typ = 2
+	}
+	return sanpltxt.NewPackage(typ, transfers, opts)
+}
+
+// validationReport converts the errors of Package.Validate to report entries
+// pointing at input records.
+func validationReport(err error, records []record, m mapping) []reportError {
+	var verrs sanpltxt.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return []reportError{{Message: err.Error()}}
+	}
+	errs := make([]reportError, len(verrs))
+	for i, e := range verrs {
+		plain := *e
+		plain.TransferIndex = -1 // the report points at the record instead
+		errs[i] = reportError{
+			Field:   e.Field,
+			Rule:    string(e.Rule),
+			Value:   e.Value,
+			Message: plain.Error(),
+		}
+		if e.Field != "" {
+			errs[i].Column = m.column(csvimport.Field(e.Field))
+		}
+		if e.TransferIndex >= 0 && e.TransferIndex < len(records) {
+			errs[i].Row = records[e.TransferIndex].row
+			errs[i].Line = records[e.TransferIndex].line
+		}
+	}
+	return errs
+}
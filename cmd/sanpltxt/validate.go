@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func runValidate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	reportFile := fs.String("report", "", "write a JSON report to `file`, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `Usage: sanpltxt validate [flags] file
+
+Check every line of an existing import file against the library's rules and
+print the errors found as file:line:column: message. Use - as file to read
+from stdin. The file may be UTF-8 or Windows-1250.
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "sanpltxt validate: expected one file")
+		fs.Usage()
+		return exitUsage
+	}
+	name := fs.Arg(0)
+
+	rep := &report{Input: name}
+	finish := func(code int) int {
+		if *reportFile == "" {
+			return code
+		}
+		if err := rep.write(*reportFile, stdout); err != nil {
+			fmt.Fprintf(stderr, "sanpltxt validate: writing report: %v\n", err)
+			if code == exitOK {
+				code = exitIO
+			}
+		}
+		return code
+	}
+
+	f, err := readImportFile(name, stdin)
+	if err != nil {
+		code := exitIO
+		e := reportError{Message: err.Error()}
+		var perr *sanpltxt.ParseError
+		if errors.As(err, &perr) {
+			code = exitInvalid
+			e = reportError{Line: perr.Line, Message: perr.Err.Error()}
+		}
+		rep.Errors = append(rep.Errors, e)
+		fmt.Fprintf(stderr, "%s: %s\n", (&importFile{name: name}).position(e), e.Message)
+		return finish(code)
+	}
+
+	rep.Transfers = len(f.transfers) + len(f.errs)
+	rep.Errors = f.validate()
+	for _, e := range rep.Errors {
+		fmt.Fprintf(stderr, "%s: %s\n", f.position(e), e.Message)
+	}
+	if len(rep.Errors) > 0 {
+		fmt.Fprintf(stderr, "%s: %d errors in %d transfers\n", name, len(rep.Errors), rep.Transfers)
+		return finish(exitInvalid)
+	}
+
+	rep.OK = true
+	fmt.Fprintf(stderr, "%s: %d transfers OK (%s, package type %s)\n", name, rep.Transfers, f.charsetName(), packageTypeName(f.typ))
+	return finish(exitOK)
+}
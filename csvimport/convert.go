@@ -0,0 +1,333 @@
+package csvimport
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+// Field identifies a transfer field that a column can hold. The names match
+// the struct fields of the sanpltxt transfer types, plus Type and TaxOffice,
+// which select the transfer type.
+type Field string
+
+// Fields.
+const (
+	FieldType           Field = "Type"
+	FieldTaxOffice      Field = "TaxOffice"
+	FieldDebitAccount   Field = "DebitAccount"
+	FieldCreditAccount  Field = "CreditAccount"
+	FieldRecipientName  Field = "RecipientName"
+	FieldAddress        Field = "Address"
+	FieldAmount         Field = "Amount"
+	FieldGrossAmount    Field = "GrossAmount"
+	FieldVATAmount      Field = "VATAmount"
+	FieldMode           Field = "Mode"
+	FieldTitle          Field = "Title"
+	FieldDate           Field = "Date"
+	FieldNIP            Field = "NIP"
+	FieldPayerName      Field = "PayerName"
+	FieldIdentifierType Field = "IdentifierType"
+	FieldIdentifier     Field = "Identifier"
+	FieldYear           Field = "Year"
+	FieldPeriodType     Field = "PeriodType"
+	FieldPeriodNumber   Field = "PeriodNumber"
+	FieldFormSymbol     Field = "FormSymbol"
+	FieldObligationID   Field = "ObligationID"
+	FieldRecipientNIP   Field = "RecipientNIP"
+	FieldInvoiceNumber  Field = "InvoiceNumber"
+	FieldFreeText       Field = "FreeText"
+)
+
+var fields = []Field{
+	FieldType, FieldTaxOffice,
+	FieldDebitAccount, FieldCreditAccount, FieldRecipientName, FieldAddress,
+	FieldAmount, FieldGrossAmount, FieldVATAmount, FieldMode, FieldTitle, FieldDate, FieldNIP,
+	FieldPayerName, FieldIdentifierType, FieldIdentifier, FieldYear, FieldPeriodType, FieldPeriodNumber, FieldFormSymbol, FieldObligationID,
+	FieldRecipientNIP, FieldInvoiceNumber, FieldFreeText,
+}
+
+// Fields returns all fields in a stable order.
+func Fields() []Field { return append([]Field(nil), fields...) }
+
+// ParseField returns the field with the given name, compared
+// case-insensitively.
+func ParseField(name string) (Field, bool) {
+	for _, f := range fields {
+		if strings.EqualFold(string(f), name) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// transferTypes maps the values accepted in the Type field to transfer type
+// numbers. Type "tax" is 3 or 4 depending on TaxOffice.
+var transferTypes = map[string]int{
+	"1": 1, "standard": 1,
+	"2": 2, "zus": 2,
+	"3": 3, "4": 4, "tax": 4,
+	"5": 5, "payroll": 5,
+	"6": 6, "split": 6, "split_payment": 6,
+}
+
+var modes = map[string]sanpltxt.TransferMode{
+	"internal": sanpltxt.ModeInternal,
+	"elixir":   sanpltxt.ModeElixir,
+	"sorbnet":  sanpltxt.ModeSORBNET,
+	"express":  sanpltxt.ModeExpressElixir,
+}
+
+// Record holds the values of one row keyed by field. Missing fields are
+// empty.
+type Record map[Field]string
+
+// FieldError describes a value that could not be converted.
+type FieldError struct {
+	Field  Field
+	Column string // header name, empty if not read from CSV
+	Rule   sanpltxt.Rule
+	Value  string
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	switch {
+	case e.Column != "":
+		return "column " + strconv.Quote(e.Column) + ": " + e.Err.Error()
+	case e.Field != "":
+		return string(e.Field) + ": " + e.Err.Error()
+	}
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// RowError lists the values of a row that could not be converted.
+type RowError struct {
+	Row    int // 1-based data row, not counting the header; 0 if unknown
+	Line   int // 1-based line in the input where the row starts; 0 if unknown
+	Errors []*FieldError
+}
+
+func (e *RowError) Error() string {
+	var b strings.Builder
+	if e.Row > 0 {
+		fmt.Fprintf(&b, "row %d", e.Row)
+		if e.Line > 0 {
+			fmt.Fprintf(&b, " (line %d)", e.Line)
+		}
+		b.WriteString(": ")
+	}
+	for i, err := range e.Errors {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the field errors, so errors.As finds the first one.
+func (e *RowError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Convert builds the transfer described by rec. The Type field holds
+// standard, zus, tax, payroll, split or a type number 1-6, and defaultType is
+// used when it is empty; for tax, TaxOffice selects type 3. Split payments
+// take GrossAmount, or Amount if it is empty, so a single amount column
+// serves all types.
+//
+// Amounts are parsed with sanpltxt.ParseAmount, dates with ParseDate and
+// modes are internal, elixir, sorbnet, express or a mode number, defaulting
+// to elixir. Values are only parsed, not validated; validate the package the
+// transfer is added to. If a value cannot be parsed, Convert returns a
+// *RowError listing every such value.
+func Convert(rec Record, defaultType string) (sanpltxt.Transfer, error) {
+	c := converter{rec: rec}
+	t := c.transfer(defaultType)
+	if len(c.errs) > 0 {
+		return nil, &RowError{Errors: c.errs}
+	}
+	return t, nil
+}
+
+// converter reads typed values from a record and collects the errors.
+type converter struct {
+	rec  Record
+	errs []*FieldError
+}
+
+func (c *converter) fail(field Field, rule sanpltxt.Rule, value string, err error) {
+	c.errs = append(c.errs, &FieldError{Field: field, Rule: rule, Value: value, Err: err})
+}
+
+func (c *converter) str(f Field) string { return strings.TrimSpace(c.rec[f]) }
+
+func (c *converter) amount(f Field) sanpltxt.Amount {
+	s := c.str(f)
+	if s == "" {
+		return 0 // reported by validation
+	}
+	a, err := sanpltxt.ParseAmount(s)
+	if err != nil {
+		c.fail(f, sanpltxt.RuleFormat, s, err)
+	}
+	return a
+}
+
+func (c *converter) mode(f Field) sanpltxt.TransferMode {
+	s := c.str(f)
+	if s == "" {
+		return sanpltxt.ModeElixir
+	}
+	if m, ok := modes[strings.ToLower(s)]; ok {
+		return m
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		c.fail(f, sanpltxt.RuleFormat, s, fmt.Errorf("invalid transfer mode %q, want a number or one of internal, elixir, sorbnet, express", s))
+	}
+	return sanpltxt.TransferMode(n)
+}
+
+func (c *converter) date(f Field) *time.Time {
+	s := c.str(f)
+	if s == "" {
+		return nil
+	}
+	d, err := ParseDate(s)
+	if err != nil {
+		c.fail(f, sanpltxt.RuleFormat, s, err)
+		return nil
+	}
+	return &d
+}
+
+func (c *converter) bool(f Field) bool {
+	s := c.str(f)
+	switch strings.ToLower(s) {
+	case "", "0", "false", "no", "n", "nie":
+		return false
+	case "1", "true", "yes", "y", "tak", "t":
+		return true
+	}
+	c.fail(f, sanpltxt.RuleFormat, s, fmt.Errorf("invalid boolean %q", s))
+	return false
+}
+
+func (c *converter) transfer(defaultType string) sanpltxt.Transfer {
+	name := c.str(FieldType)
+	if name == "" {
+		name = defaultType
+	}
+	if name == "" {
+		c.fail(FieldType, sanpltxt.RuleRequired, "", errors.New("transfer type is required"))
+		return nil
+	}
+	typ, ok := transferTypes[strings.ToLower(name)]
+	if !ok {
+		c.fail(FieldType, sanpltxt.RuleInvalidValue, name, fmt.Errorf("unknown transfer type %q", name))
+		return nil
+	}
+	if typ == 4 && strings.EqualFold(name, "tax") && c.bool(FieldTaxOffice) {
+		typ = 3
+	}
+
+	switch typ {
+	case 1:
+		return &sanpltxt.Standard{
+			DebitAccount:  c.str(FieldDebitAccount),
+			CreditAccount: c.str(FieldCreditAccount),
+			RecipientName: c.str(FieldRecipientName),
+			Address:       c.str(FieldAddress),
+			Amount:        c.amount(FieldAmount),
+			Mode:          c.mode(FieldMode),
+			Title:         c.str(FieldTitle),
+			Date:          c.date(FieldDate),
+			NIP:           c.str(FieldNIP),
+		}
+	case 2:
+		return &sanpltxt.ZUS{
+			DebitAccount:  c.str(FieldDebitAccount),
+			CreditAccount: c.str(FieldCreditAccount),
+			RecipientName: c.str(FieldRecipientName),
+			Address:       c.str(FieldAddress),
+			Amount:        c.amount(FieldAmount),
+			Title:         c.str(FieldTitle),
+			Date:          c.date(FieldDate),
+		}
+	case 3, 4:
+		return &sanpltxt.Tax{
+			TaxOffice:      typ == 3,
+			DebitAccount:   c.str(FieldDebitAccount),
+			CreditAccount:  c.str(FieldCreditAccount),
+			RecipientName:  c.str(FieldRecipientName),
+			Address:        c.str(FieldAddress),
+			Amount:         c.amount(FieldAmount),
+			Date:           c.date(FieldDate),
+			PayerName:      c.str(FieldPayerName),
+			IdentifierType: sanpltxt.IdentifierType(c.str(FieldIdentifierType)),
+			Identifier:     c.str(FieldIdentifier),
+			Year:           c.str(FieldYear),
+			PeriodType:     sanpltxt.PeriodType(c.str(FieldPeriodType)),
+			PeriodNumber:   c.str(FieldPeriodNumber),
+			FormSymbol:     c.str(FieldFormSymbol),
+			ObligationID:   c.str(FieldObligationID),
+		}
+	case 5:
+		return &sanpltxt.Payroll{
+			DebitAccount:  c.str(FieldDebitAccount),
+			CreditAccount: c.str(FieldCreditAccount),
+			RecipientName: c.str(FieldRecipientName),
+			Address:       c.str(FieldAddress),
+			Amount:        c.amount(FieldAmount),
+			Mode:          c.mode(FieldMode),
+			Title:         c.str(FieldTitle),
+			Date:          c.date(FieldDate),
+		}
+	}
+
+	gross := FieldGrossAmount
+	if c.str(gross) == "" {
+		gross = FieldAmount
+	}
+	return &sanpltxt.SplitPayment{
+		DebitAccount:  c.str(FieldDebitAccount),
+		CreditAccount: c.str(FieldCreditAccount),
+		RecipientName: c.str(FieldRecipientName),
+		Address:       c.str(FieldAddress),
+		GrossAmount:   c.amount(gross),
+		Mode:          c.mode(FieldMode),
+		VATAmount:     c.amount(FieldVATAmount),
+		RecipientNIP:  c.str(FieldRecipientNIP),
+		InvoiceNumber: c.str(FieldInvoiceNumber),
+		FreeText:      c.str(FieldFreeText),
+		Date:          c.date(FieldDate),
+	}
+}
+
+// dateLayouts are the date formats accepted by ParseDate.
+var dateLayouts = []string{"02-01-2006", time.DateOnly, "02.01.2006", "2.1.2006", "2006.01.02", "02/01/2006"}
+
+// ParseDate parses a date as exported by Polish spreadsheets: DD-MM-YYYY,
+// YYYY-MM-DD, DD.MM.YYYY (with or without leading zeros), YYYY.MM.DD or
+// DD/MM/YYYY.
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, want DD-MM-YYYY or YYYY-MM-DD", s)
+}
@@ -0,0 +1,272 @@
+// Package csvimport converts CSV exports from spreadsheets into sanpltxt
+// transfers.
+//
+// The first row of the input is a header. A Mapping declares which header
+// holds which Field; headers that are not mapped are matched against the
+// field names case-insensitively, so a file with headers such as
+// "DebitAccount" and "Amount" needs no mapping. Every following row describes
+// one transfer, converted as described in Convert.
+//
+//	r := csvimport.NewReader(f, &csvimport.Options{
+//		Mapping: csvimport.Mapping{
+//			"Rachunek": csvimport.FieldDebitAccount,
+//			"Konto":    csvimport.FieldCreditAccount,
+//			"Odbiorca": csvimport.FieldRecipientName,
+//			"Adres":    csvimport.FieldAddress,
+//			"Kwota":    csvimport.FieldAmount,
+//			"Tytuł":    csvimport.FieldTitle,
+//			"Data":     csvimport.FieldDate,
+//		},
+//		DefaultType: "standard",
+//	})
+//	for t, err := range r.All() {
+//		...
+//	}
+package csvimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+// Mapping maps header names to the fields their columns hold. Header names
+// are compared case-insensitively, ignoring surrounding spaces.
+type Mapping map[string]Field
+
+// Options configures a Reader.
+type Options struct {
+	Mapping Mapping
+
+	// Comma is the field separator. If zero, it is ';' if the header has
+	// more semicolons than commas and ',' otherwise.
+	Comma rune
+
+	// DefaultType is the transfer type of rows without a Type value, such as
+	// "payroll". See Convert.
+	DefaultType string
+}
+
+// Reader reads transfers from CSV input. The input may be UTF-8, with or
+// without a byte order mark, or Windows-1250 as saved by Excel; it is read
+// into memory on the first call to Next.
+//
+// A row that cannot be converted is reported as *RowError and does not stop
+// the reader; the next call continues with the following row. Errors reading
+// the input or its header are permanent.
+type Reader struct {
+	r    io.Reader
+	opts Options
+
+	csv     *csv.Reader
+	header  []string
+	columns []Field // field of each column, "" if ignored
+	row     int
+	line    int
+	err     error // sticky header or read error
+	started bool
+}
+
+// NewReader returns a Reader that reads transfers from r. opts may be nil.
+func NewReader(r io.Reader, opts *Options) *Reader {
+	rd := &Reader{r: r}
+	if opts != nil {
+		rd.opts = *opts
+	}
+	return rd
+}
+
+// Header reads the header if needed and returns the header names.
+func (r *Reader) Header() ([]string, error) {
+	if err := r.readHeader(); err != nil {
+		return nil, err
+	}
+	return r.header, nil
+}
+
+// Next returns the next transfer. It returns io.EOF when there are no more
+// rows. Rows with all cells empty are skipped.
+func (r *Reader) Next() (sanpltxt.Transfer, error) {
+	if err := r.readHeader(); err != nil {
+		return nil, err
+	}
+	for {
+		rec, err := r.csv.Read()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		r.row++
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				r.line = perr.StartLine
+				return nil, &RowError{Row: r.row, Line: r.line, Errors: []*FieldError{{Rule: sanpltxt.RuleFormat, Err: perr.Err}}}
+			}
+			r.err = err
+			return nil, err
+		}
+		r.line, _ = r.csv.FieldPos(0)
+		if isBlank(rec) {
+			r.row--
+			continue
+		}
+
+		values := make(Record)
+		columnOf := make(map[Field]string)
+		for i, v := range rec {
+			if i < len(r.columns) && r.columns[i] != "" {
+				values[r.columns[i]] = v
+				columnOf[r.columns[i]] = r.header[i]
+			}
+		}
+		t, err := Convert(values, r.opts.DefaultType)
+		if err != nil {
+			var rerr *RowError
+			if errors.As(err, &rerr) {
+				rerr.Row, rerr.Line = r.row, r.line
+				for _, ferr := range rerr.Errors {
+					ferr.Column = columnOf[ferr.Field]
+				}
+			}
+			return nil, err
+		}
+		return t, nil
+	}
+}
+
+// All returns an iterator over the remaining transfers. Iteration stops after
+// the first permanent error.
+func (r *Reader) All() iter.Seq2[sanpltxt.Transfer, error] {
+	return func(yield func(sanpltxt.Transfer, error) bool) {
+		for {
+			t, err := r.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(t, err) || (err != nil && r.err != nil) {
+				return
+			}
+		}
+	}
+}
+
+// Row returns the 1-based number of the last data row read, not counting the
+// header or blank rows.
+func (r *Reader) Row() int { return r.row }
+
+// Line returns the line in the input where the last row read starts.
+func (r *Reader) Line() int { return r.line }
+
+// Column returns the header name of the column that holds f, or "" if no
+// column does.
+func (r *Reader) Column(f Field) string {
+	for i, c := range r.columns {
+		if c == f {
+			return r.header[i]
+		}
+	}
+	return ""
+}
+
+func (r *Reader) readHeader() error {
+	if r.started {
+		return r.err
+	}
+	r.started = true
+	r.err = r.init()
+	return r.err
+}
+
+func (r *Reader) init() error {
+	data, err := io.ReadAll(r.r)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	s := string(data)
+	if !utf8.Valid(data) {
+		if s, err = sanpltxt.FromWindows1250(data); err != nil {
+			return err
+		}
+	}
+
+	comma := r.opts.Comma
+	if comma == 0 {
+		first, _, _ := strings.Cut(s, "\n")
+		comma = ','
+		if strings.Count(first, ";") > strings.Count(first, ",") {
+			comma = ';'
+		}
+	}
+
+	r.csv = csv.NewReader(strings.NewReader(s))
+	r.csv.Comma = comma
+	r.csv.FieldsPerRecord = -1 // spreadsheets drop trailing empty cells
+	r.csv.TrimLeadingSpace = true
+
+	if r.header, err = r.csv.Read(); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("reading header: %w", err)
+	}
+	for i, h := range r.header {
+		r.header[i] = strings.TrimSpace(h)
+	}
+	return r.resolve()
+}
+
+// resolve assigns fields to columns: mapped headers first, then headers named
+// after fields that are not mapped to another column.
+func (r *Reader) resolve() error {
+	r.columns = make([]Field, len(r.header))
+	seen := make(map[Field]string)
+	assign := func(i int, f Field) error {
+		if prev, dup := seen[f]; dup {
+			return fmt.Errorf("columns %q and %q both map to field %s", prev, r.header[i], f)
+		}
+		seen[f] = r.header[i]
+		r.columns[i] = f
+		return nil
+	}
+
+	for _, h := range slices.Sorted(maps.Keys(r.opts.Mapping)) {
+		f, ok := ParseField(string(r.opts.Mapping[h]))
+		if !ok {
+			return fmt.Errorf("mapping of %q: unknown field %q", h, r.opts.Mapping[h])
+		}
+		i := slices.IndexFunc(r.header, func(name string) bool { return strings.EqualFold(name, strings.TrimSpace(h)) })
+		if i < 0 {
+			return fmt.Errorf("mapped column %q not found in header", h)
+		}
+		if err := assign(i, f); err != nil {
+			return err
+		}
+	}
+	for i, h := range r.header {
+		if f, ok := ParseField(h); ok && r.columns[i] == "" && seen[f] == "" {
+			if err := assign(i, f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isBlank(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
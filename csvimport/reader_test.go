@@ -0,0 +1,201 @@
+package csvimport_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+	"github.com/amwolff/sanpltxt/csvimport"
+)
+
+func date(year, month, day int) *time.Time {
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+var polishMapping = csvimport.Mapping{
+	"Rachunek": csvimport.FieldDebitAccount,
+	"Konto":    csvimport.FieldCreditAccount,
+	"Odbiorca": csvimport.FieldRecipientName,
+	"Adres":    csvimport.FieldAddress,
+	"Kwota":    csvimport.FieldAmount,
+	"Tytuł":    csvimport.FieldTitle,
+	"Data":     "date",
+}
+
+func TestReader_Windows1250Semicolons(t *testing.T) {
+	input, err := sanpltxt.ToWindows1250("Rachunek;Konto;Odbiorca;Adres;Kwota;Tytuł;Data;Uwagi\r\n" +
+		"51109010430000000100111111;50102055581111103350100016;Jan Nowak;Poznań ul. Swojska 17 06-123;1 000,12;Wynagrodzenie za miesiąc;01-09-2020;premia\r\n" +
+		";;;;;;;\r\n" +
+		"51109010430000000100111111;50102055581111103350100016;Anna Nowak;Poznań;\"2 500,00\";Wynagrodzenie;2020-09-01\r\n")
+	assert.NoError(t, err)
+
+	r := csvimport.NewReader(strings.NewReader(string(input)), &csvimport.Options{
+		Mapping:     polishMapping,
+		DefaultType: "payroll",
+	})
+
+	var got []sanpltxt.Transfer
+	var lines []int
+	for tr, err := range r.All() {
+		assert.NoError(t, err)
+		got = append(got, tr)
+		lines = append(lines, r.Line())
+	}
+	assert.DeepEqual(t, got, []sanpltxt.Transfer{
+		&sanpltxt.Payroll{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jan Nowak",
+			Address:       "Poznań ul. Swojska 17 06-123",
+			Amount:        100012,
+			Mode:          sanpltxt.ModeElixir,
+			Title:         "Wynagrodzenie za miesiąc",
+			Date:          date(2020, 9, 1),
+		},
+		&sanpltxt.Payroll{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Anna Nowak",
+			Address:       "Poznań",
+			Amount:        250000,
+			Mode:          sanpltxt.ModeElixir,
+			Title:         "Wynagrodzenie",
+			Date:          date(2020, 9, 1),
+		},
+	})
+	assert.DeepEqual(t, lines, []int{2, 4})
+	assert.Equal(t, r.Row(), 2)
+	assert.Equal(t, r.Column(csvimport.FieldAmount), "Kwota")
+}
+
+func TestReader_RowErrors(t *testing.T) {
+	input := "type,DebitAccount,CreditAccount,RecipientName,Amount,VATAmount,Date,Mode\n" +
+		"split,51109010430000000100111111,88102055581111103350100011,Jan Nowak,\"123,50\",\"23,09\",30-09-2020,express\n" +
+		"standard,51109010430000000100111111,50102055581111103350100016,Jan Nowak,12.345,,31-02-2020,fast\n" +
+		"wire,,,,,,,\n"
+
+	r := csvimport.NewReader(strings.NewReader(input), nil)
+
+	tr, err := r.Next()
+	assert.NoError(t, err)
+	sp := tr.(*sanpltxt.SplitPayment)
+	assert.Equal(t, sp.GrossAmount, sanpltxt.Amount(12350))
+	assert.Equal(t, sp.VATAmount, sanpltxt.Amount(2309))
+	assert.Equal(t, sp.Mode, sanpltxt.ModeExpressElixir)
+
+	_, err = r.Next()
+	var rerr *csvimport.RowError
+	assert.True(t, errors.As(err, &rerr))
+	assert.Equal(t, rerr.Row, 2)
+	assert.Equal(t, rerr.Line, 3)
+	assert.Equal(t, len(rerr.Errors), 3)
+	assert.Equal(t, rerr.Errors[0].Field, csvimport.FieldAmount)
+	assert.Equal(t, rerr.Errors[0].Column, "Amount")
+	assert.Equal(t, rerr.Errors[0].Value, "12.345")
+	assert.Equal(t, rerr.Errors[1].Field, csvimport.FieldMode)
+	assert.Equal(t, rerr.Errors[2].Field, csvimport.FieldDate)
+
+	_, err = r.Next()
+	assert.True(t, errors.As(err, &rerr))
+	assert.Equal(t, err.Error(), `row 3 (line 4): column "type": unknown transfer type "wire"`)
+
+	_, err = r.Next()
+	assert.Equal(t, err, io.EOF)
+}
+
+func TestReader_MalformedRows(t *testing.T) {
+	input := "Amount;Title\n" +
+		"x\"y;2\n" +
+		"\"1,00\";ok\n" +
+		"\"2,00;unclosed\n" +
+		"3,00;lost\n"
+
+	r := csvimport.NewReader(strings.NewReader(input), &csvimport.Options{DefaultType: "standard"})
+
+	_, err := r.Next()
+	var rerr *csvimport.RowError
+	assert.True(t, errors.As(err, &rerr))
+	assert.Equal(t, rerr.Row, 1)
+	assert.Equal(t, rerr.Line, 2)
+	assert.Equal(t, rerr.Errors[0].Rule, sanpltxt.RuleFormat)
+
+	tr, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, tr.(*sanpltxt.Standard).Amount, sanpltxt.Amount(100))
+	assert.Equal(t, r.Line(), 3)
+
+	// An unclosed quote swallows the rest of the input.
+	_, err = r.Next()
+	assert.True(t, errors.As(err, &rerr))
+	assert.Equal(t, rerr.Row, 3)
+	assert.Equal(t, rerr.Line, 4)
+
+	_, err = r.Next()
+	assert.Equal(t, err, io.EOF)
+}
+
+func TestReader_HeaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		mapping csvimport.Mapping
+	}{
+		{"empty", "", nil},
+		{"missing mapped column", "Kwota;Konto\n", csvimport.Mapping{"Tytuł": csvimport.FieldTitle}},
+		{"unknown field", "Kwota;Konto\n", csvimport.Mapping{"Kwota": "Sum"}},
+		{"duplicate field", "Kwota;Amount\n", csvimport.Mapping{"Kwota": csvimport.FieldAmount, "Amount": csvimport.FieldAmount}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n int
+			for _, err := range csvimport.NewReader(strings.NewReader(tt.input), &csvimport.Options{Mapping: tt.mapping}).All() {
+				assert.Error(t, err)
+				n++
+			}
+			assert.Equal(t, n, 1)
+		})
+	}
+
+	// A mapped column takes precedence over a column named after the field.
+	r := csvimport.NewReader(strings.NewReader("Kwota;Amount\n"), &csvimport.Options{Mapping: csvimport.Mapping{"Kwota": csvimport.FieldAmount}})
+	_, err := r.Header()
+	assert.NoError(t, err)
+	assert.Equal(t, r.Column(csvimport.FieldAmount), "Kwota")
+}
+
+func TestParseDate(t *testing.T) {
+	for _, s := range []string{"01-09-2020", "2020-09-01", "01.09.2020", "1.9.2020", " 01/09/2020 "} {
+		d, err := csvimport.ParseDate(s)
+		assert.NoError(t, err)
+		assert.DeepEqual(t, &d, date(2020, 9, 1))
+	}
+	for _, s := range []string{"", "2020-13-01", "09-01-20", "1 września 2020"} {
+		_, err := csvimport.ParseDate(s)
+		assert.Error(t, err)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tr, err := csvimport.Convert(csvimport.Record{
+		csvimport.FieldType:           "tax",
+		csvimport.FieldTaxOffice:      "tak",
+		csvimport.FieldAmount:         "PLN 1.000,00",
+		csvimport.FieldIdentifierType: "N",
+		csvimport.FieldIdentifier:     "7850000007",
+		csvimport.FieldPeriodType:     "M",
+	}, "")
+	assert.NoError(t, err)
+	tax := tr.(*sanpltxt.Tax)
+	assert.True(t, tax.TaxOffice)
+	assert.Equal(t, tax.Amount, sanpltxt.Amount(100000))
+	assert.Equal(t, tax.PeriodType, sanpltxt.PeriodMonth)
+
+	_, err = csvimport.Convert(csvimport.Record{}, "")
+	assert.Equal(t, err.Error(), "Type: transfer type is required")
+}
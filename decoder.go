@@ -0,0 +1,178 @@
+package sanpltxt
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"iter"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+type charset int
+
+const (
+	charsetUnknown charset = iota
+	charsetUTF8
+	charsetWindows1250
+)
+
+// Decoder reads a package from an io.Reader one transfer at a time, so memory
+// use does not grow with the size of the input.
+//
+// Malformed transfer lines are reported as *ParseError and do not stop the
+// decoder; the next call continues with the following line. Errors reading
+// the header or the underlying io.Reader are permanent.
+type Decoder struct {
+	s       *bufio.Scanner
+	charset charset
+	line    int
+	typ     int
+	err     error // sticky header or read error
+	started bool
+}
+
+// NewDecoder returns a Decoder that reads a package from r with encoding based
+// on options. If opts is nil, the encoding is detected from the input: a file
+// is treated as UTF-8 if its first non-ASCII line is valid UTF-8 and as
+// Windows-1250 otherwise.
+func NewDecoder(r io.Reader, opts *PackageOptions) *Decoder {
+	d := &Decoder{s: bufio.NewScanner(r)}
+	if opts != nil {
+		if opts.EncodeUTF8 {
+			d.charset = charsetUTF8
+		} else {
+			d.charset = charsetWindows1250
+		}
+	}
+	return d
+}
+
+// Type reads the header if needed and returns the package type: 1 = regular,
+// 2 = payroll.
+func (d *Decoder) Type() (int, error) {
+	if err := d.readHeader(); err != nil {
+		return 0, err
+	}
+	return d.typ, nil
+}
+
+// Next returns the next transfer in the package. It returns io.EOF when there
+// are no more transfers.
+func (d *Decoder) Next() (Transfer, error) {
+	if err := d.readHeader(); err != nil {
+		return nil, err
+	}
+	for {
+		line, err := d.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		t, err := ParseTransfer(line)
+		if err != nil {
+			return nil, withLine(err, d.line)
+		}
+		return t, nil
+	}
+}
+
+// All returns an iterator over the remaining transfers. Iteration stops after
+// the first permanent error.
+func (d *Decoder) All() iter.Seq2[Transfer, error] {
+	return func(yield func(Transfer, error) bool) {
+		for {
+			t, err := d.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(t, err) || (err != nil && d.err != nil) {
+				return
+			}
+		}
+	}
+}
+
+// Line returns the number of the last line read, counting the header as line
+// 1. After Next returns a transfer, it is the line the transfer was read from.
+func (d *Decoder) Line() int { return d.line }
+
+// UTF8 reports whether the input is decoded as UTF-8. Until a non-ASCII line
+// has been read, a Decoder created without options reports false.
+func (d *Decoder) UTF8() bool { return d.charset == charsetUTF8 }
+
+func (d *Decoder) readHeader() error {
+	if d.started {
+		return d.err
+	}
+	d.started = true
+
+	line, err := d.readLine()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		d.err = &ParseError{Line: 1, Err: err}
+		return d.err
+	}
+	if d.typ, err = parseHeader(line); err != nil {
+		d.err = &ParseError{Line: 1, Err: err}
+	}
+	return d.err
+}
+
+func (d *Decoder) readLine() (string, error) {
+	if d.err != nil {
+		return "", d.err
+	}
+	if !d.s.Scan() {
+		if err := d.s.Err(); err != nil {
+			d.err = err
+			return "", err
+		}
+		return "", io.EOF
+	}
+	d.line++
+
+	b := d.s.Bytes()
+	if d.line == 1 {
+		b = bytes.TrimPrefix(b, utf8BOM)
+	}
+	return d.decode(b)
+}
+
+func (d *Decoder) decode(b []byte) (string, error) {
+	if d.charset == charsetUnknown {
+		if isASCII(b) {
+			return string(b), nil
+		}
+		if utf8.Valid(b) {
+			d.charset = charsetUTF8
+		} else {
+			d.charset = charsetWindows1250
+		}
+	}
+	if d.charset == charsetUTF8 {
+		return string(b), nil
+	}
+	decoded, err := charmap.Windows1250.NewDecoder().Bytes(b)
+	if err != nil {
+		return "", &ParseError{Line: d.line, Err: err}
+	}
+	return string(decoded), nil
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package sanpltxt_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+const payrollFile = "4120414|2\n" +
+	"5|51109010430000000100111111|50102055581111103350100016|Jan Nowak|Poznań ul. Swojska 17 06-123|1000,12|1|Wynagrodzenie za miesiąc|01-09-2020|\n" +
+	"5|51109010430000000100111111|50102055581111103350100016|Anna Nowak|Poznań ul. Swojska 17 06-123|2000|1|Wynagrodzenie za miesiąc||\n"
+
+func TestDecoder_DetectsCharset(t *testing.T) {
+	win1250, err := sanpltxt.ToWindows1250(payrollFile)
+	assert.NoError(t, err)
+
+	for name, input := range map[string]string{
+		"utf-8":        payrollFile,
+		"utf-8 bom":    "\xef\xbb\xbf" + payrollFile,
+		"windows-1250": string(win1250),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dec := sanpltxt.NewDecoder(strings.NewReader(input), nil)
+
+			typ, err := dec.Type()
+			assert.NoError(t, err)
+			assert.Equal(t, typ, 2)
+
+			var names []string
+			for tr, err := range dec.All() {
+				assert.NoError(t, err)
+				p := tr.(*sanpltxt.Payroll)
+				assert.Equal(t, p.Address, "Poznań ul. Swojska 17 06-123")
+				names = append(names, p.RecipientName)
+			}
+			assert.DeepEqual(t, names, []string{"Jan Nowak", "Anna Nowak"})
+			assert.Equal(t, dec.UTF8(), name != "windows-1250")
+		})
+	}
+}
+
+func TestDecoder_ContinuesAfterParseError(t *testing.T) {
+	lines := strings.Split(payrollFile, "\n")
+	input := lines[0] + "\r\n5|broken|\r\n\r\n" + lines[1] + "\r\n"
+
+	dec := sanpltxt.NewDecoder(strings.NewReader(input), &sanpltxt.PackageOptions{EncodeUTF8: true})
+
+	_, err := dec.Next()
+	var perr *sanpltxt.ParseError
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, perr.Line, 2)
+
+	tr, err := dec.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, tr.(*sanpltxt.Payroll).RecipientName, "Jan Nowak")
+	assert.Equal(t, dec.Line(), 4)
+
+	_, err = dec.Next()
+	assert.Equal(t, err, io.EOF)
+}
+
+func TestDecoder_HeaderError(t *testing.T) {
+	dec := sanpltxt.NewDecoder(strings.NewReader(""), nil)
+	_, err := dec.Next()
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	var n int
+	for _, err := range sanpltxt.NewDecoder(strings.NewReader("garbage\n1|x|\n"), nil).All() {
+		assert.Error(t, err)
+		n++
+	}
+	assert.Equal(t, n, 1)
+}
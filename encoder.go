@@ -0,0 +1,164 @@
+package sanpltxt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var errEncoderClosed = errors.New("encoder is closed")
+
+// Encoder writes a package to an io.Writer one transfer at a time, so memory
+// use does not grow with the number of transfers.
+//
+// A transfer that fails validation is not written; Encode returns its error
+// and the encoder continues with the next transfer. Close reports the errors
+// of all rejected transfers.
+type Encoder struct {
+	w       *bufio.Writer
+	charset *encoding.Encoder // nil for UTF-8
+	typ     int
+	line    strings.Builder
+
+	sanitize   bool
+	onSanitize func(SanitizeChange)
+
+	n       int     // number of transfers passed to Encode
+	errs    []error // per-transfer errors
+	err     error   // sticky header or write error
+	started bool
+	closed  bool
+}
+
+// NewEncoder returns an Encoder that writes a package of the given type
+// (1 = regular, 2 = payroll) to w with encoding based on options.
+func NewEncoder(w io.Writer, typ int, opts *PackageOptions) *Encoder {
+	e := &Encoder{
+		w:   bufio.NewWriter(w),
+		typ: typ,
+	}
+	if opts == nil || !opts.EncodeUTF8 {
+		e.charset = charmap.Windows1250.NewEncoder()
+	}
+	if opts != nil {
+		e.sanitize = opts.Sanitize
+		e.onSanitize = opts.OnSanitize
+	}
+	return e
+}
+
+// Encode validates t and writes it as the next line of the package. The
+// header is written before the first transfer. If sanitization is enabled in
+// options, a sanitized copy of t is validated and written instead.
+func (e *Encoder) Encode(t Transfer) error {
+	if e.closed {
+		return errEncoderClosed
+	}
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	i := e.n
+	e.n++
+
+	if e.sanitize {
+		var changes []SanitizeChange
+		t, changes = SanitizeTransfer(t)
+		if e.onSanitize != nil {
+			for _, c := range changes {
+				c.TransferIndex = i
+				e.onSanitize(c)
+			}
+		}
+	}
+
+	e.line.Reset()
+	err := checkPackageTransfer(e.typ, t)
+	if err == nil {
+		err = marshalRecord(&e.line, t)
+	}
+	if err == nil {
+		e.line.WriteString("\n")
+		err = e.write(e.line.String())
+	}
+	if err != nil {
+		err = annotate(err, i, t)
+		if !isValidationError(err) {
+			err = fmt.Errorf("transfer %d: %w", i, err)
+		}
+		if e.err == nil {
+			e.errs = append(e.errs, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	if err := e.w.Flush(); err != nil {
+		e.err = err
+	}
+	return e.err
+}
+
+// Close writes the header if no transfer was encoded, flushes buffered data
+// and returns the errors of all rejected transfers joined together. It does
+// not close the underlying io.Writer.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return errEncoderClosed
+	}
+	if err := e.writeHeader(); err == nil {
+		_ = e.Flush()
+	}
+	e.closed = true
+
+	if e.err != nil {
+		return errors.Join(append(e.errs, e.err)...)
+	}
+	return errors.Join(e.errs...)
+}
+
+func (e *Encoder) writeHeader() error {
+	if e.started {
+		return e.err
+	}
+	e.started = true
+
+	if err := checkPackageType(e.typ); err != nil {
+		e.err = err
+		return err
+	}
+	if err := e.write(FormatVersion + "|" + strconv.Itoa(e.typ) + "\n"); err != nil {
+		e.err = err
+		return err
+	}
+	return nil
+}
+
+func (e *Encoder) write(s string) error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.charset == nil {
+		_, err := e.w.WriteString(s)
+		e.err = err
+		return err
+	}
+	b, err := e.charset.String(s)
+	if err != nil {
+		return err // unencodable characters only affect this transfer
+	}
+	_, e.err = e.w.WriteString(b)
+	return e.err
+}
@@ -0,0 +1,98 @@
+package sanpltxt_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func TestEncoder_Windows1250(t *testing.T) {
+	payroll := &sanpltxt.Payroll{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100016",
+		RecipientName: "Jan Nowak",
+		Address:       "Poznań ul. Swojska 17 06-123",
+		Amount:        100012,
+		Mode:          sanpltxt.ModeElixir,
+		Title:         "Wynagrodzenie za miesiąc",
+		Date:          date(2020, 9, 1),
+	}
+
+	var buf bytes.Buffer
+	enc := sanpltxt.NewEncoder(&buf, 2, nil)
+	for range 3 {
+		assert.NoError(t, enc.Encode(payroll))
+	}
+	assert.NoError(t, enc.Close())
+
+	want, err := sanpltxt.NewPackage(2, []sanpltxt.Transfer{payroll, payroll, payroll}, nil).MarshalBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, buf.String(), string(want))
+
+	decoded, err := sanpltxt.FromWindows1250(buf.Bytes())
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(decoded, "Poznań"))
+}
+
+func TestEncoder_ReportsErrorsPerTransfer(t *testing.T) {
+	valid := &sanpltxt.Payroll{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100016",
+		RecipientName: "Jan Nowak",
+		Address:       "Poznań",
+		Amount:        100,
+		Mode:          sanpltxt.ModeElixir,
+		Title:         "Pensja",
+	}
+	invalid := *valid
+	invalid.RecipientName = ""
+
+	var buf bytes.Buffer
+	enc := sanpltxt.NewEncoder(&buf, 2, &sanpltxt.PackageOptions{EncodeUTF8: true})
+	assert.NoError(t, enc.Encode(valid))
+	assert.Error(t, enc.Encode(&invalid))
+	assert.Error(t, enc.Encode(&sanpltxt.Standard{}))
+	assert.NoError(t, enc.Encode(valid))
+
+	err := enc.Close()
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "transfer 1:"))
+	assert.True(t, strings.Contains(err.Error(), "transfer 2:"))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, len(lines), 3)
+	assert.Equal(t, lines[0], "4120414|2")
+}
+
+func TestEncoder_EmptyPackage(t *testing.T) {
+	var buf bytes.Buffer
+	enc := sanpltxt.NewEncoder(&buf, 1, nil)
+	assert.NoError(t, enc.Close())
+	assert.Equal(t, buf.String(), "4120414|1\n")
+
+	assert.Error(t, sanpltxt.NewEncoder(&buf, 3, nil).Close())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestEncoder_WriteError(t *testing.T) {
+	enc := sanpltxt.NewEncoder(failingWriter{}, 1, nil)
+	assert.NoError(t, enc.Encode(&sanpltxt.Standard{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100016",
+		RecipientName: "Jerzy Kowalski",
+		Address:       "Warszawa",
+		Amount:        12312,
+		Mode:          sanpltxt.ModeElixir,
+		Title:         "zasielenie konta",
+	}))
+	assert.Error(t, enc.Flush())
+	assert.Error(t, enc.Close())
+}
@@ -0,0 +1,157 @@
+package sanpltxt_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+// checkLines asserts that every line of marshaled package content parses back
+// to a transfer equal to the corresponding one in want and that marshals to
+// the same line.
+func checkLines(t *testing.T, got string, want []sanpltxt.Transfer) {
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	assert.Equal(t, len(lines), 1+len(want))
+	assert.Equal(t, lines[0], "4120414|1")
+
+	for i, line := range lines[1:] {
+		tr, err := sanpltxt.ParseTransfer(line)
+		assert.NoError(t, err)
+		assert.DeepEqual(t, tr, want[i])
+		again, err := tr.Marshal()
+		assert.NoError(t, err)
+		assert.Equal(t, again, line)
+	}
+}
+
+func FuzzPackage_Marshal(f *testing.F) {
+	f.Add("Jerzy Kowalski", "Warszawa ul. Kaliska 123", "zasielenie konta", "id.zobowiazania", "05", "07", "PIT5", "5/2018", false)
+	f.Add("a|b", "c\nd", "e\r\n1|x", "|", "0|", "1\n", "A|", "/INV/", true)
+	f.Add("Müller & Co.", "Straße", "FV — 1", "x;y", "", "", "vat-7", "FV — 1", true)
+	f.Add("Jan Nowak", "Warszawa", "Faktura", "id", "05", "07", "PIT5", "A/TXT/B", false)
+	f.Add("Jan Nowak", "Warszawa", "Faktura", "id", "05", "07", "PIT5", "A/TXT", false)
+
+	f.Fuzz(func(t *testing.T, name, address, title, obligation, year, period, form, invoice string, sanitize bool) {
+		transfers := []sanpltxt.Transfer{
+			&sanpltxt.Standard{
+				DebitAccount:  "51109010430000000100111111",
+				CreditAccount: "50102055581111103350100016",
+				RecipientName: name,
+				Address:       address,
+				Amount:        12312,
+				Mode:          sanpltxt.ModeElixir,
+				Title:         title,
+			},
+			&sanpltxt.Tax{
+				TaxOffice:      true,
+				DebitAccount:   "51109010430000000100111111",
+				CreditAccount:  "06101014690039392223000000",
+				RecipientName:  name,
+				Address:        address,
+				Amount:         100000,
+				PayerName:      name,
+				IdentifierType: sanpltxt.IdentifierOther,
+				Identifier:     obligation,
+				Year:           year,
+				PeriodType:     sanpltxt.PeriodMonth,
+				PeriodNumber:   period,
+				FormSymbol:     form,
+				ObligationID:   obligation,
+			},
+			&sanpltxt.SplitPayment{
+				DebitAccount:  "51109010430000000100111111",
+				CreditAccount: "88102055581111103350100011",
+				RecipientName: name,
+				Address:       address,
+				GrossAmount:   12350,
+				Mode:          sanpltxt.ModeElixir,
+				VATAmount:     2309,
+				RecipientNIP:  "8960005673",
+				InvoiceNumber: invoice,
+				FreeText:      title,
+			},
+		}
+		pkg := sanpltxt.NewPackage(1, transfers, &sanpltxt.PackageOptions{Sanitize: sanitize})
+
+		// Validate and Marshal must agree.
+		verr := pkg.Validate()
+		got, err := pkg.Marshal()
+		assert.Equal(t, err == nil, verr == nil)
+		if err != nil {
+			return
+		}
+		if sanitize {
+			for i, tr := range transfers {
+				transfers[i], _ = sanpltxt.SanitizeTransfer(tr)
+			}
+		}
+		checkLines(t, got, transfers)
+	})
+}
+
+func TestCheckRecord_RejectsDelimiters(t *testing.T) {
+	// The line-level check holds even for transfer types with no whitelist.
+	pkg := sanpltxt.NewPackage(1, []sanpltxt.Transfer{rawTransfer("1|a|b\n2|c|")}, nil)
+	_, err := pkg.Marshal()
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "line break"))
+}
+
+func TestTax_IdentifierOtherDelimiter(t *testing.T) {
+	tax := &sanpltxt.Tax{
+		TaxOffice:      true,
+		DebitAccount:   "51109010430000000100111111",
+		CreditAccount:  "06101014690039392223000000",
+		RecipientName:  "Urząd Skarbowy",
+		Amount:         100000,
+		PayerName:      "Jan Nowak",
+		IdentifierType: sanpltxt.IdentifierOther,
+		Identifier:     "A|B",
+		FormSymbol:     "PIT5",
+	}
+	_, err := tax.Marshal()
+	var verr *sanpltxt.ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, verr.Field, "Identifier")
+	assert.Equal(t, verr.Rule, sanpltxt.RuleInvalidChars)
+
+	assert.Error(t, sanpltxt.NewPackage(1, []sanpltxt.Transfer{tax}, nil).Validate())
+}
+
+func TestSplitPayment_InvoiceNumberDelimiter(t *testing.T) {
+	for _, invoice := range []string{"FV/TXT/1", "FV/TXT"} {
+		sp := &sanpltxt.SplitPayment{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "88102055581111103350100011",
+			RecipientName: "Jan Nowak",
+			GrossAmount:   12350,
+			Mode:          sanpltxt.ModeElixir,
+			VATAmount:     2309,
+			RecipientNIP:  "8960005673",
+			InvoiceNumber: invoice,
+			FreeText:      "Faktura",
+		}
+		_, err := sp.Marshal()
+		var verr *sanpltxt.ValidationError
+		assert.True(t, errors.As(err, &verr))
+		assert.Equal(t, verr.Field, "InvoiceNumber")
+		assert.Equal(t, verr.Rule, sanpltxt.RuleDelimiter)
+	}
+}
+
+func TestPackage_ValidateChecksRecord(t *testing.T) {
+	pkg := sanpltxt.NewPackage(1, []sanpltxt.Transfer{rawTransfer("1|a|\n1|b|")}, nil)
+	err := pkg.Validate()
+	var verr *sanpltxt.ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, verr.Rule, sanpltxt.RuleDelimiter)
+	assert.Equal(t, verr.TransferIndex, 0)
+}
+
+type rawTransfer string
+
+func (r rawTransfer) Marshal() (string, error) { return string(r), nil }
@@ -5,4 +5,5 @@ go 1.25.5
 require (
 	github.com/zeebo/assert v1.3.1
 	golang.org/x/text v0.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
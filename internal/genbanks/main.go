@@ -0,0 +1,147 @@
+// Command genbanks regenerates banks.csv from the list of settlement numbers
+// (numery rozliczeniowe) published by Narodowy Bank Polski.
+//
+// The input is a delimited text file with a header row, in UTF-8 or
+// Windows-1250. The default column names have not been checked against a
+// current export: compare them with its header and adjust them with the -col
+// flags if they differ.
+//
+// Usage:
+//
+//	go run ./internal/genbanks [-o banks.csv] [-sep TAB] file...
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+type bank struct {
+	sortCode, name, branch, bic string
+}
+
+func main() {
+	var (
+		out       = flag.String("o", "banks.csv", "output file")
+		sep       = flag.String("sep", "TAB", "input field separator (TAB or a single character)")
+		colCode   = flag.String("col-sort-code", "NumerRozliczeniowy", "sort code column")
+		colName   = flag.String("col-name", "NazwaBanku", "bank name column")
+		colBranch = flag.String("col-branch", "NazwaJednostki", "branch name column")
+		colBIC    = flag.String("col-bic", "BIC", "BIC column")
+	)
+	flag.Parse()
+
+	comma := '\t'
+	if *sep != "TAB" {
+		r, n := utf8.DecodeRuneInString(*sep)
+		if n != len(*sep) {
+			log.Fatalf("separator must be a single character, got %q", *sep)
+		}
+		comma = r
+	}
+
+	byCode, err := readBanks(flag.Args(), comma, []string{*colCode, *colName, *colBranch, *colBIC})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, formatTable(byCode), 0o600); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readBanks reads the branches listed in the named files, keyed by their
+// 8-digit sort codes, and adds an entry for each bank under its 4-digit code.
+func readBanks(names []string, comma rune, columns []string) (map[string]bank, error) {
+	byCode := make(map[string]bank)
+	for _, name := range names {
+		banks, err := readFile(name, comma, columns)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, b := range banks {
+			byCode[b.sortCode] = b
+			// The first branch seen also describes the bank as a whole.
+			if _, ok := byCode[b.sortCode[:4]]; !ok {
+				byCode[b.sortCode[:4]] = bank{sortCode: b.sortCode[:4], name: b.name, bic: b.bic}
+			}
+		}
+	}
+	if len(byCode) == 0 {
+		return nil, errors.New("no input records")
+	}
+	return byCode, nil
+}
+
+func readFile(name string, comma rune, columns []string) ([]bank, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	if !utf8.Valid(data) {
+		if s, err = sanpltxt.FromWindows1250(data); err != nil {
+			return nil, err
+		}
+	}
+
+	r := csv.NewReader(strings.NewReader(s))
+	r.Comma = comma
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	idx := make([]int, len(columns))
+	for i, c := range columns {
+		if idx[i] = slices.Index(header, c); idx[i] < 0 {
+			return nil, fmt.Errorf("column %q not found in header %q", c, header)
+		}
+	}
+
+	var banks []bank
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return banks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(i int) string {
+			if idx[i] >= len(rec) {
+				return ""
+			}
+			return strings.Join(strings.Fields(strings.ReplaceAll(rec[idx[i]], ";", ",")), " ")
+		}
+		b := bank{sortCode: field(0), name: field(1), branch: field(2), bic: field(3)}
+		if len(b.sortCode) != 8 || strings.Trim(b.sortCode, "0123456789") != "" {
+			continue // not a settlement number, e.g. a trailing summary row
+		}
+		banks = append(banks, b)
+	}
+}
+
+// formatTable renders the table in the format read by sanpltxt.BankInfo.
+func formatTable(byCode map[string]bank) []byte {
+	var b strings.Builder
+	b.WriteString("# Code generated by internal/genbanks; DO NOT EDIT.\n")
+	b.WriteString("# sort_code;name;branch;bic\n")
+	for _, code := range slices.Sorted(maps.Keys(byCode)) {
+		e := byCode[code]
+		fmt.Fprintf(&b, "%s;%s;%s;%s\n", e.sortCode, e.name, e.branch, e.bic)
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+// nbpSample is laid out like the export genbanks expects: tab-separated with
+// a header row, extra columns and a trailing summary row. The cooperative bank
+// is made up.
+const nbpSample = "NumerRozliczeniowy\tNazwaBanku\tNazwaJednostki\tMiejscowosc\tBIC\n" +
+	"10901043\tSantander Bank Polska\t1 Oddział w Warszawie\tWarszawa\tWBKPPLPP\n" +
+	"10901056\tSantander Bank Polska\t2 Oddział  w Poznaniu\tPoznań\tWBKPPLPP\n" +
+	"89990001\tBank Spółdzielczy w Przykładowie\tCentrala; Oddział\tPrzykładowo\tGBWCPLPP\n" +
+	"Razem: 3\n"
+
+const wantTable = `# Code generated by internal/genbanks; DO NOT EDIT.
+# sort_code;name;branch;bic
+1090;Santander Bank Polska;;WBKPPLPP
+10901043;Santander Bank Polska;1 Oddział w Warszawie;WBKPPLPP
+10901056;Santander Bank Polska;2 Oddział w Poznaniu;WBKPPLPP
+8999;Bank Spółdzielczy w Przykładowie;;GBWCPLPP
+89990001;Bank Spółdzielczy w Przykładowie;Centrala, Oddział;GBWCPLPP
+`
+
+var defaultColumns = []string{"NumerRozliczeniowy", "NazwaBanku", "NazwaJednostki", "BIC"}
+
+func writeFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(p, content, 0o600))
+	return p
+}
+
+func TestReadBanks(t *testing.T) {
+	cp1250, err := sanpltxt.ToWindows1250(nbpSample)
+	assert.NoError(t, err)
+
+	for name, content := range map[string][]byte{"utf-8": []byte(nbpSample), "windows-1250": cp1250} {
+		t.Run(name, func(t *testing.T) {
+			byCode, err := readBanks([]string{writeFile(t, "plewibnra.txt", content)}, '\t', defaultColumns)
+			assert.NoError(t, err)
+			assert.Equal(t, string(formatTable(byCode)), wantTable)
+		})
+	}
+}
+
+func TestReadBanks_Errors(t *testing.T) {
+	in := writeFile(t, "plewibnra.txt", []byte(nbpSample))
+
+	_, err := readBanks([]string{in}, '\t', []string{"Numer", "NazwaBanku", "NazwaJednostki", "BIC"})
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), `column "Numer" not found`))
+
+	_, err = readBanks([]string{writeFile(t, "empty.txt", []byte(strings.SplitAfter(nbpSample, "\n")[0]))}, '\t', defaultColumns)
+	assert.Error(t, err)
+}
@@ -0,0 +1,274 @@
+package sanpltxt
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+//go:embed package.schema.json
+var jsonSchema []byte
+
+// JSONSchema returns the JSON Schema of the JSON representation of a Package.
+//
+// A transfer is an object with the fields named in the struct tags of its
+// type and a "type" discriminator: "standard", "zus", "tax", "payroll" or
+// "split". Amounts are decimal strings such as "1234.56", dates are
+// YYYY-MM-DD and a missing date means immediate execution. A package is an
+// object holding its type, 1 or 2, and its transfers:
+//
+//	{
+//		"type": 1,
+//		"transfers": [
+//			{
+//				"type": "standard",
+//				"debit_account": "51109010430000000100111111",
+//				"credit_account": "50102055581111103350100016",
+//				"recipient_name": "Jan Nowak",
+//				"address": "Warszawa ul. Kaliska 123 00-123",
+//				"amount": "1234.56",
+//				"mode": 1,
+//				"title": "Faktura 1/2020",
+//				"date": "2020-09-01"
+//			}
+//		]
+//	}
+//
+// Decoding also accepts amounts in any form accepted by ParseAmount and as
+// JSON numbers. The schema checks the structure only; use Package.Validate for
+// the format rules.
+func JSONSchema() []byte { return bytes.Clone(jsonSchema) }
+
+// Transfer type names used as the JSON discriminator.
+const (
+	jsonStandard = "standard"
+	jsonZUS      = "zus"
+	jsonTax      = "tax"
+	jsonPayroll  = "payroll"
+	jsonSplit    = "split"
+)
+
+// jsonDate is a date encoded as YYYY-MM-DD.
+type jsonDate time.Time
+
+func (d jsonDate) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, time.Time(d).Format(time.DateOnly)), nil
+}
+
+func (d *jsonDate) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid date %s, want YYYY-MM-DD", data)
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, want YYYY-MM-DD", s)
+	}
+	*d = jsonDate(t)
+	return nil
+}
+
+func checkJSONType(got, want string) error {
+	if got != "" && got != want {
+		return fmt.Errorf("transfer type %q, want %q", got, want)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the transfer with its "type" discriminator.
+func (s Standard) MarshalJSON() ([]byte, error) {
+	type fields Standard
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date,omitempty"`
+	}{jsonStandard, (*fields)(&s), (*jsonDate)(s.Date)})
+}
+
+// UnmarshalJSON decodes the transfer. The "type" discriminator may be omitted.
+func (s *Standard) UnmarshalJSON(data []byte) error {
+	type fields Standard
+	v := struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date"`
+	}{fields: (*fields)(s)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	s.Date = (*time.Time)(v.Date)
+	return checkJSONType(v.Type, jsonStandard)
+}
+
+// MarshalJSON encodes the transfer with its "type" discriminator.
+func (z ZUS) MarshalJSON() ([]byte, error) {
+	type fields ZUS
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date,omitempty"`
+	}{jsonZUS, (*fields)(&z), (*jsonDate)(z.Date)})
+}
+
+// UnmarshalJSON decodes the transfer. The "type" discriminator may be omitted.
+func (z *ZUS) UnmarshalJSON(data []byte) error {
+	type fields ZUS
+	v := struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date"`
+	}{fields: (*fields)(z)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	z.Date = (*time.Time)(v.Date)
+	return checkJSONType(v.Type, jsonZUS)
+}
+
+// MarshalJSON encodes the transfer with its "type" discriminator. Type 3 and
+// 4 transfers are both "tax", told apart by "tax_office".
+func (t Tax) MarshalJSON() ([]byte, error) {
+	type fields Tax
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date,omitempty"`
+	}{jsonTax, (*fields)(&t), (*jsonDate)(t.Date)})
+}
+
+// UnmarshalJSON decodes the transfer. The "type" discriminator may be omitted.
+func (t *Tax) UnmarshalJSON(data []byte) error {
+	type fields Tax
+	v := struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date"`
+	}{fields: (*fields)(t)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	t.Date = (*time.Time)(v.Date)
+	return checkJSONType(v.Type, jsonTax)
+}
+
+// MarshalJSON encodes the transfer with its "type" discriminator.
+func (p Payroll) MarshalJSON() ([]byte, error) {
+	type fields Payroll
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date,omitempty"`
+	}{jsonPayroll, (*fields)(&p), (*jsonDate)(p.Date)})
+}
+
+// UnmarshalJSON decodes the transfer. The "type" discriminator may be omitted.
+func (p *Payroll) UnmarshalJSON(data []byte) error {
+	type fields Payroll
+	v := struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date"`
+	}{fields: (*fields)(p)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	p.Date = (*time.Time)(v.Date)
+	return checkJSONType(v.Type, jsonPayroll)
+}
+
+// MarshalJSON encodes the transfer with its "type" discriminator.
+func (s SplitPayment) MarshalJSON() ([]byte, error) {
+	type fields SplitPayment
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date,omitempty"`
+	}{jsonSplit, (*fields)(&s), (*jsonDate)(s.Date)})
+}
+
+// UnmarshalJSON decodes the transfer. The "type" discriminator may be omitted.
+func (s *SplitPayment) UnmarshalJSON(data []byte) error {
+	type fields SplitPayment
+	v := struct {
+		Type string `json:"type"`
+		*fields
+		Date *jsonDate `json:"date"`
+	}{fields: (*fields)(s)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	s.Date = (*time.Time)(v.Date)
+	return checkJSONType(v.Type, jsonSplit)
+}
+
+// UnmarshalTransferJSON decodes a transfer of the type named by its "type"
+// discriminator.
+func UnmarshalTransferJSON(data []byte) (Transfer, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+	var t Transfer
+	switch head.Type {
+	case jsonStandard:
+		t = new(Standard)
+	case jsonZUS:
+		t = new(ZUS)
+	case jsonTax:
+		t = new(Tax)
+	case jsonPayroll:
+		t = new(Payroll)
+	case jsonSplit:
+		t = new(SplitPayment)
+	case "":
+		return nil, errors.New("transfer type is required")
+	default:
+		return nil, fmt.Errorf("unknown transfer type %q", head.Type)
+	}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// MarshalJSON encodes the package type and transfers. Options are not
+// encoded.
+func (p *Package) MarshalJSON() ([]byte, error) {
+	transfers := p.transfers
+	if transfers == nil {
+		transfers = []Transfer{}
+	}
+	return json.Marshal(struct {
+		Type      int        `json:"type"`
+		Transfers []Transfer `json:"transfers"`
+	}{p.typ, transfers})
+}
+
+// UnmarshalJSON decodes the package type and transfers, keeping the options
+// of p. Transfers are decoded with UnmarshalTransferJSON; errors name the
+// 0-based index of the transfer.
+func (p *Package) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Type      int               `json:"type"`
+		Transfers []json.RawMessage `json:"transfers"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	transfers := make([]Transfer, len(v.Transfers))
+	for i, raw := range v.Transfers {
+		t, err := UnmarshalTransferJSON(raw)
+		if err != nil {
+			return fmt.Errorf("transfer %d: %w", i, err)
+		}
+		transfers[i] = t
+	}
+	p.typ, p.transfers = v.Type, transfers
+	return nil
+}
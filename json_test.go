@@ -0,0 +1,159 @@
+package sanpltxt_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func jsonTransfers() []sanpltxt.Transfer {
+	return []sanpltxt.Transfer{
+		&sanpltxt.Standard{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jerzy Kowalski",
+			Address:       "Warszawa ul. Kaliska 123 00-123",
+			Amount:        12312,
+			Mode:          sanpltxt.ModeElixir,
+			Title:         "zasielenie konta",
+			Date:          date(2020, 9, 1),
+			NIP:           "7850000007",
+		},
+		&sanpltxt.ZUS{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "83101010230000261395100000",
+			RecipientName: "ZUS",
+			Address:       "Warszawa",
+			Amount:        50000,
+			Title:         "składki",
+			Date:          date(2020, 10, 15),
+		},
+		&sanpltxt.Tax{
+			TaxOffice:      true,
+			DebitAccount:   "51109010430000000100111111",
+			CreditAccount:  "50102055581111103350100016",
+			RecipientName:  "Urząd Skarbowy",
+			Address:        "Warszawa",
+			Amount:         100000,
+			Date:           date(2020, 10, 20),
+			PayerName:      "Jan Nowak",
+			IdentifierType: sanpltxt.IdentifierNIP,
+			Identifier:     "7850000007",
+			Year:           "20",
+			PeriodType:     sanpltxt.PeriodMonth,
+			PeriodNumber:   "09",
+			FormSymbol:     "VAT7",
+			ObligationID:   "ABC",
+		},
+		&sanpltxt.Payroll{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jan Nowak",
+			Address:       "Poznań",
+			Amount:        100012,
+			Mode:          sanpltxt.ModeExpressElixir,
+			Title:         "Wynagrodzenie",
+			Date:          date(2020, 9, 28),
+		},
+		&sanpltxt.SplitPayment{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "88102055581111103350100011",
+			RecipientName: "Firma",
+			Address:       "Kraków",
+			GrossAmount:   12350,
+			Mode:          sanpltxt.ModeElixir,
+			VATAmount:     2309,
+			RecipientNIP:  "7850000007",
+			InvoiceNumber: "FV 1/2020",
+			FreeText:      "zaliczka",
+			Date:          date(2020, 9, 30),
+		},
+	}
+}
+
+func TestStandard_JSON(t *testing.T) {
+	b, err := json.Marshal(jsonTransfers()[0])
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), `{"type":"standard","debit_account":"51109010430000000100111111",`+
+		`"credit_account":"50102055581111103350100016","recipient_name":"Jerzy Kowalski",`+
+		`"address":"Warszawa ul. Kaliska 123 00-123","amount":"123.12","mode":1,`+
+		`"title":"zasielenie konta","nip":"7850000007","date":"2020-09-01"}`)
+
+	var s sanpltxt.Standard
+	assert.NoError(t, json.Unmarshal(b, &s))
+	assert.DeepEqual(t, &s, jsonTransfers()[0])
+
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"zus"}`), &s))
+	assert.Error(t, json.Unmarshal([]byte(`{"date":"01-09-2020"}`), &s))
+}
+
+func TestPackage_JSON(t *testing.T) {
+	pkg := sanpltxt.NewPackage(1, jsonTransfers(), nil)
+	b, err := json.Marshal(pkg)
+	assert.NoError(t, err)
+
+	var got sanpltxt.Package
+	assert.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, got.Type(), 1)
+	assert.DeepEqual(t, got.Transfers(), pkg.Transfers())
+
+	b, err = json.Marshal(sanpltxt.NewPackage(2, nil, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), `{"type":2,"transfers":[]}`)
+
+	for in, want := range map[string]string{
+		`{"type":1,"transfers":[{"type":"standard"},{"type":"wire"}]}`: `transfer 1: unknown transfer type "wire"`,
+		`{"type":1,"transfers":[{}]}`:                                  "transfer 0: transfer type is required",
+		`{"type":1,"transfers":[{"type":"zus","amount":"1.005"}]}`:     `transfer 0: invalid amount "1.005": more than two decimal places`,
+	} {
+		err := json.Unmarshal([]byte(in), &got)
+		assert.Error(t, err)
+		assert.Equal(t, err.Error(), want)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"$defs"`
+	}
+	assert.NoError(t, json.Unmarshal(sanpltxt.JSONSchema(), &schema))
+
+	// Every field is described and every field that is always encoded is
+	// required. The fixtures set all fields.
+	for _, tr := range jsonTransfers() {
+		b, err := json.Marshal(tr)
+		assert.NoError(t, err)
+		var fields map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(b, &fields))
+		var typ string
+		assert.NoError(t, json.Unmarshal(fields["type"], &typ))
+
+		def, ok := schema.Defs[typ]
+		assert.True(t, ok)
+		for name := range fields {
+			_, ok := def.Properties[name]
+			assert.True(t, ok)
+		}
+		assert.Equal(t, len(def.Properties), len(fields))
+
+		required := []string{"type"}
+		st := reflect.TypeOf(tr).Elem()
+		for i := range st.NumField() {
+			if tag := st.Field(i).Tag.Get("json"); !strings.HasSuffix(tag, ",omitempty") {
+				required = append(required, tag)
+			}
+		}
+		slices.Sort(required)
+		slices.Sort(def.Required)
+		assert.DeepEqual(t, def.Required, required)
+	}
+}
@@ -0,0 +1,96 @@
+package sanpltxt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MergeOptions controls MergePackages.
+type MergeOptions struct {
+	// DropDuplicates leaves duplicate transfers out of the merged package.
+	// They are reported either way.
+	DropDuplicates bool
+}
+
+// Duplicate identifies a transfer that repeats an earlier one: the same
+// transfer type, debit and credit account, amount, title and execution date.
+// Positions are indexes into the packages passed to MergePackages and into
+// their transfers.
+type Duplicate struct {
+	Package, Index                 int // the repeated transfer
+	OriginalPackage, OriginalIndex int // its first occurrence
+}
+
+// MergePackages concatenates the transfers of packages into a single package
+// with the type and options of the first one. All packages must have the same
+// type. Duplicate transfers are reported whether they repeat a transfer from
+// another package or from the same one; account numbers are compared in
+// normalized form, see NormalizeNRB. For tax transfers the identifying tax
+// fields take the place of the title, and for split payments the title is
+// built from the VAT fields. Transfers of other Transfer implementations are
+// duplicates only if they marshal to the same line.
+func MergePackages(opts *MergeOptions, packages ...*Package) (*Package, []Duplicate, error) {
+	if len(packages) == 0 {
+		return nil, nil, errors.New("no packages to merge")
+	}
+	typ := packages[0].typ
+	for i, p := range packages[1:] {
+		if p.typ != typ {
+			return nil, nil, fmt.Errorf("package %d has type %d, want type %d of package 0", i+1, p.typ, typ)
+		}
+	}
+
+	type position struct{ pkg, index int }
+	var (
+		merged     = packages[0].withTransfers(nil)
+		duplicates []Duplicate
+		seen       = make(map[duplicateKey]position)
+	)
+	for i, p := range packages {
+		for j, t := range p.transfers {
+			k, ok := duplicateKeyOf(t)
+			if !ok {
+				merged.transfers = append(merged.transfers, t)
+				continue
+			}
+			if first, ok := seen[k]; ok {
+				duplicates = append(duplicates, Duplicate{i, j, first.pkg, first.index})
+				if opts != nil && opts.DropDuplicates {
+					continue
+				}
+			} else {
+				seen[k] = position{i, j}
+			}
+			merged.transfers = append(merged.transfers, t)
+		}
+	}
+	return merged, duplicates, nil
+}
+
+// duplicateKey holds the fields that identify a transfer as a duplicate.
+type duplicateKey struct {
+	typ           int
+	debit, credit string
+	amount        Amount
+	title, date   string
+	line          string // marshaled transfer, for unknown transfer types
+}
+
+// duplicateKeyOf returns the duplicate key of t. It returns false if t cannot
+// be compared, which is the case for a transfer of an unknown type that does
+// not marshal.
+func duplicateKeyOf(t Transfer) (duplicateKey, bool) {
+	info := infoOf(t)
+	if info.typ == 0 {
+		line, err := t.Marshal()
+		return duplicateKey{line: line}, err == nil
+	}
+	return duplicateKey{
+		typ:    info.typ,
+		debit:  NormalizeNRB(info.debit),
+		credit: NormalizeNRB(info.credit),
+		amount: info.amount,
+		title:  info.title,
+		date:   dateKey(info.date),
+	}, true
+}
@@ -0,0 +1,73 @@
+package sanpltxt_test
+
+import (
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func TestMergePackages(t *testing.T) {
+	a := balancePackage()
+	b := sanpltxt.NewPackage(1, []sanpltxt.Transfer{
+		&sanpltxt.Standard{
+			DebitAccount:  "PL51 1090 1043 0000 0001 0011 1111",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jerzy Kowalski",
+			Address:       "Warszawa ul. Kaliska 123 00-123",
+			Amount:        20000,
+			Mode:          sanpltxt.ModeExpressElixir,
+			Title:         "media",
+			Date:          date(2020, 9, 10),
+		},
+		&sanpltxt.Standard{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jerzy Kowalski",
+			Address:       "Warszawa ul. Kaliska 123 00-123",
+			Amount:        20000,
+			Mode:          sanpltxt.ModeElixir,
+			Title:         "media",
+			Date:          date(2020, 9, 11),
+		},
+	}, nil)
+
+	merged, dups, err := sanpltxt.MergePackages(nil, a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, merged.Type(), 1)
+	assert.Equal(t, len(merged.Transfers()), 7)
+	assert.DeepEqual(t, dups, []sanpltxt.Duplicate{
+		{Package: 1, Index: 0, OriginalPackage: 0, OriginalIndex: 1},
+	})
+
+	merged, dups, err = sanpltxt.MergePackages(&sanpltxt.MergeOptions{DropDuplicates: true}, a, b, b)
+	assert.NoError(t, err)
+	assert.Equal(t, len(merged.Transfers()), 6)
+	assert.DeepEqual(t, merged.Transfers()[5], b.Transfers()[1])
+	assert.DeepEqual(t, dups, []sanpltxt.Duplicate{
+		{Package: 1, Index: 0, OriginalPackage: 0, OriginalIndex: 1},
+		{Package: 2, Index: 0, OriginalPackage: 0, OriginalIndex: 1},
+		{Package: 2, Index: 1, OriginalPackage: 1, OriginalIndex: 1},
+	})
+}
+
+func TestMergePackages_UnknownTypes(t *testing.T) {
+	a := sanpltxt.NewPackage(1, []sanpltxt.Transfer{rawTransfer("1|a|")}, nil)
+	b := sanpltxt.NewPackage(1, []sanpltxt.Transfer{rawTransfer("1|b|"), rawTransfer("1|a|")}, nil)
+
+	merged, dups, err := sanpltxt.MergePackages(&sanpltxt.MergeOptions{DropDuplicates: true}, a, b)
+	assert.NoError(t, err)
+	assert.DeepEqual(t, merged.Transfers(), []sanpltxt.Transfer{rawTransfer("1|a|"), rawTransfer("1|b|")})
+	assert.DeepEqual(t, dups, []sanpltxt.Duplicate{
+		{Package: 1, Index: 1, OriginalPackage: 0, OriginalIndex: 0},
+	})
+}
+
+func TestMergePackages_Errors(t *testing.T) {
+	_, _, err := sanpltxt.MergePackages(nil)
+	assert.Error(t, err)
+
+	_, _, err = sanpltxt.MergePackages(nil, balancePackage(), sanpltxt.NewPackage(2, nil, nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+package sanpltxt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError reports a malformed line or field in an import file.
+type ParseError struct {
+	Line  int // 1-based line number, 0 if unknown
+	Field int // 1-based field number within the line, 0 for the whole line
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.Line > 0 {
+		b.WriteString("line ")
+		b.WriteString(strconv.Itoa(e.Line))
+	}
+	if e.Field > 0 {
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("field ")
+		b.WriteString(strconv.Itoa(e.Field))
+	}
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// ParsePackage parses package content from a UTF-8 string. Transfers are
+// decoded but not validated; marshal the package to validate them.
+func ParsePackage(s string) (*Package, error) {
+	return decodePackage(NewDecoder(strings.NewReader(s), &PackageOptions{EncodeUTF8: true}))
+}
+
+// ParsePackageBytes parses package content with encoding based on options.
+// If opts is nil, the encoding is detected as described in NewDecoder.
+func ParsePackageBytes(b []byte, opts *PackageOptions) (*Package, error) {
+	return decodePackage(NewDecoder(bytes.NewReader(b), opts))
+}
+
+func decodePackage(d *Decoder) (*Package, error) {
+	typ, err := d.Type()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Package{typ: typ}
+	for t, err := range d.All() {
+		if err != nil {
+			return nil, err
+		}
+		p.transfers = append(p.transfers, t)
+	}
+	p.options.EncodeUTF8 = d.UTF8()
+	return p, nil
+}
+
+// ParseTransfer parses a single transfer line. The returned Transfer is one
+// of *Standard, *ZUS, *Tax, *Payroll or *SplitPayment.
+func ParseTransfer(line string) (Transfer, error) {
+	line = strings.TrimSuffix(line, "\r")
+	fields := strings.Split(strings.TrimSuffix(line, "|"), "|")
+
+	var t interface {
+		Transfer
+		unmarshal(fields []string) error
+	}
+	switch fields[0] {
+	case "1":
+		t = new(Standard)
+	case "2":
+		t = new(ZUS)
+	case "3", "4":
+		t = new(Tax)
+	case "5":
+		t = new(Payroll)
+	case "6":
+		t = new(SplitPayment)
+	default:
+		return nil, &ParseError{Field: 1, Err: fmt.Errorf("unknown transfer type %q", fields[0])}
+	}
+
+	if err := t.unmarshal(fields); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func parseHeader(line string) (int, error) {
+	line = strings.TrimSuffix(line, "\r")
+	version, typ, ok := strings.Cut(line, "|")
+	if !ok {
+		return 0, errors.New("header must be in the form " + FormatVersion + "|<type>")
+	}
+	if version != FormatVersion {
+		return 0, fmt.Errorf("unsupported format version %q, want %s", version, FormatVersion)
+	}
+	switch typ {
+	case "1":
+		return 1, nil
+	case "2":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("package type must be 1 (regular) or 2 (payroll), got %q", typ)
+}
+
+func withLine(err error, line int) error {
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		perr.Line = line
+		return perr
+	}
+	return &ParseError{Line: line, Err: err}
+}
+
+func fieldError(field int, err error) error {
+	return &ParseError{Field: field + 1, Err: err}
+}
+
+func checkFieldCount(fields []string, want int) error {
+	if len(fields) != want {
+		return &ParseError{Err: fmt.Errorf("type %s transfer must have %d fields, got %d", fields[0], want, len(fields))}
+	}
+	return nil
+}
+
+func parseAmount(fields []string, i int) (Amount, error) {
+	s := fields[i]
+	zloty, grosze, hasGrosze := strings.Cut(s, ",")
+	if zloty == "" || !isDigitsOnly(zloty) || (hasGrosze && (len(grosze) != 2 || !isDigitsOnly(grosze))) {
+		return 0, fieldError(i, fmt.Errorf("invalid amount %q", s))
+	}
+	z, err := strconv.ParseInt(zloty, 10, 64)
+	if err == nil && z > math.MaxInt64/100-1 {
+		err = strconv.ErrRange
+	}
+	if err != nil {
+		return 0, fieldError(i, fmt.Errorf("invalid amount %q: %w", s, err))
+	}
+	a := Amount(z * 100)
+	if hasGrosze {
+		g, _ := strconv.ParseInt(grosze, 10, 64)
+		a += Amount(g)
+	}
+	return a, nil
+}
+
+func parseMode(fields []string, i int) (TransferMode, error) {
+	n, err := strconv.Atoi(fields[i])
+	if err != nil {
+		return 0, fieldError(i, fmt.Errorf("invalid transfer mode %q", fields[i]))
+	}
+	return TransferMode(n), nil
+}
+
+func parseDate(fields []string, i int) (*time.Time, error) {
+	if fields[i] == "" {
+		return nil, nil
+	}
+	d, err := time.Parse(dateFormat, fields[i])
+	if err != nil {
+		return nil, fieldError(i, fmt.Errorf("invalid date %q, want DD-MM-YYYY", fields[i]))
+	}
+	return &d, nil
+}
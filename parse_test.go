@@ -0,0 +1,145 @@
+package sanpltxt_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func TestParsePackage_RoundTrip(t *testing.T) {
+	pkg := sanpltxt.NewPackage(1, []sanpltxt.Transfer{
+		&sanpltxt.Standard{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jerzy Kowalski",
+			Address:       "Warszawa ul. Kaliska 123 00-123",
+			Amount:        12312,
+			Mode:          sanpltxt.ModeElixir,
+			Title:         "zasielenie konta",
+			Date:          date(2020, 9, 1),
+			NIP:           "7850000007",
+		},
+		&sanpltxt.ZUS{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "20600000020260111122223333",
+			RecipientName: "ZUS",
+			Address:       "Warszawa ul. Szamocka 3,5 01748",
+			Amount:        31994,
+			Title:         "Skladka ZUS",
+		},
+		&sanpltxt.Tax{
+			TaxOffice:      true,
+			DebitAccount:   "51109010430000000100111111",
+			CreditAccount:  "50102055581111103350100016",
+			RecipientName:  "Urząd Skarbowy",
+			Amount:         100000,
+			Date:           date(2020, 9, 1),
+			PayerName:      "Jan Kowalski",
+			IdentifierType: sanpltxt.IdentifierNIP,
+			Identifier:     "7850000007",
+			Year:           "20",
+			PeriodType:     sanpltxt.PeriodMonth,
+			PeriodNumber:   "08",
+			FormSymbol:     "VAT7",
+		},
+		&sanpltxt.SplitPayment{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "88102055581111103350100011",
+			RecipientName: "Jan Nowak",
+			GrossAmount:   12350,
+			Mode:          sanpltxt.ModeElixir,
+			VATAmount:     2309,
+			RecipientNIP:  "8960005673",
+			InvoiceNumber: "5/2018",
+			FreeText:      "Faktura 5/2018",
+			Date:          date(2020, 9, 30),
+		},
+	}, nil)
+
+	want, err := pkg.Marshal()
+	assert.NoError(t, err)
+
+	parsed, err := sanpltxt.ParsePackage(want)
+	assert.NoError(t, err)
+	assert.Equal(t, parsed.Type(), 1)
+	assert.Equal(t, len(parsed.Transfers()), 4)
+	assert.DeepEqual(t, parsed.Transfers(), pkg.Transfers())
+
+	got, err := parsed.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, got, want)
+}
+
+func TestParsePackageBytes_Windows1250(t *testing.T) {
+	b, err := sanpltxt.ToWindows1250("4120414|2\r\n5|51109010430000000100111111|50102055581111103350100016|Jan Nowak|Poznań ul. Swojska 17 06-123|1000,12|1|Wynagrodzenie za miesiąc|01-09-2020|\r\n")
+	assert.NoError(t, err)
+
+	pkg, err := sanpltxt.ParsePackageBytes(b, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.Type(), 2)
+	assert.Equal(t, len(pkg.Transfers()), 1)
+
+	p, ok := pkg.Transfers()[0].(*sanpltxt.Payroll)
+	assert.True(t, ok)
+	assert.Equal(t, p.Address, "Poznań ul. Swojska 17 06-123")
+	assert.Equal(t, p.Amount, sanpltxt.Amount(100012))
+	assert.Equal(t, p.Title, "Wynagrodzenie za miesiąc")
+	assert.DeepEqual(t, p.Date, date(2020, 9, 1))
+}
+
+func TestParseTransfer_SplitPaymentTitle(t *testing.T) {
+	tr, err := sanpltxt.ParseTransfer("6|51109010430000000100111111|88102055581111103350100011|Jan Nowak|Warszawa ul. Mickiewicza 11 02-222|123,50|1|/VAT/23,09/IDC/8960005673/INV/5/2018/TXT/Faktura 5/2018|30-09-2020|")
+	assert.NoError(t, err)
+
+	sp, ok := tr.(*sanpltxt.SplitPayment)
+	assert.True(t, ok)
+	assert.Equal(t, sp.GrossAmount, sanpltxt.Amount(12350))
+	assert.Equal(t, sp.VATAmount, sanpltxt.Amount(2309))
+	assert.Equal(t, sp.RecipientNIP, "8960005673")
+	assert.Equal(t, sp.InvoiceNumber, "5/2018")
+	assert.Equal(t, sp.FreeText, "Faktura 5/2018")
+}
+
+func TestParseTransfer_SplitPaymentTitleFirstTXT(t *testing.T) {
+	// The first /TXT/ ends the invoice number, even if written by a program
+	// that did not reject /TXT/ in invoice numbers.
+	tr, err := sanpltxt.ParseTransfer("6|51109010430000000100111111|88102055581111103350100011|Jan Nowak||123,50|1|/VAT/23,09/IDC/8960005673/INV/FV/TXT/1/TXT/Faktura|30-09-2020|")
+	assert.NoError(t, err)
+
+	sp, ok := tr.(*sanpltxt.SplitPayment)
+	assert.True(t, ok)
+	assert.Equal(t, sp.InvoiceNumber, "FV")
+	assert.Equal(t, sp.FreeText, "1/TXT/Faktura")
+}
+
+func TestParsePackage_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+		field int
+	}{
+		{"bad version", "1234567|1\n", 1, 0},
+		{"bad package type", "4120414|3\n", 1, 0},
+		{"unknown transfer type", "4120414|1\n7|x|\n", 2, 1},
+		{"field count", "4120414|1\n1|51109010430000000100111111|\n", 2, 0},
+		{"bad amount", "4120414|2\n5|51109010430000000100111111|50102055581111103350100016|Jan Nowak|Poznań|12.50|1|Pensja||\n", 2, 6},
+		{"bad date", "4120414|2\n5|51109010430000000100111111|50102055581111103350100016|Jan Nowak|Poznań|12,50|1|Pensja|2020-09-01|\n", 2, 9},
+		{"bad split title", "4120414|1\n6|51109010430000000100111111|88102055581111103350100011|Jan Nowak||123,50|1|Faktura||\n", 2, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sanpltxt.ParsePackage(tt.input)
+			assert.Error(t, err)
+
+			var perr *sanpltxt.ParseError
+			assert.True(t, errors.As(err, &perr))
+			assert.Equal(t, perr.Line, tt.line)
+			assert.Equal(t, perr.Field, tt.field)
+		})
+	}
+}
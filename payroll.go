@@ -7,14 +7,14 @@ import (
 
 // Payroll is a Type 5 transfer (salary payment). Only allowed in payroll packages.
 type Payroll struct {
-	DebitAccount  string
-	CreditAccount string
-	RecipientName string
-	Address       string
-	Amount        Amount
-	Mode          TransferMode
-	Title         string
-	Date          *time.Time
+	DebitAccount  string       `json:"debit_account"`
+	CreditAccount string       `json:"credit_account"`
+	RecipientName string       `json:"recipient_name"`
+	Address       string       `json:"address"`
+	Amount        Amount       `json:"amount"`
+	Mode          TransferMode `json:"mode"`
+	Title         string       `json:"title"`
+	Date          *time.Time   `json:"date,omitempty"`
 }
 
 var _ Transfer = (*Payroll)(nil)
@@ -22,15 +22,20 @@ var _ Transfer = (*Payroll)(nil)
 // Marshal returns the transfer in Santander format.
 func (p *Payroll) Marshal() (string, error) {
 	var b strings.Builder
-	if err := p.marshal(&b); err != nil {
+	if err := marshalRecord(&b, p); err != nil {
 		return "", err
 	}
 	return b.String(), nil
 }
 
+// RecipientBank returns the bank holding CreditAccount. See BankInfo.
+func (p *Payroll) RecipientBank() (Bank, bool) {
+	return BankInfo(p.CreditAccount)
+}
+
 func (p *Payroll) marshal(b *strings.Builder) error {
 	if err := p.validate(); err != nil {
-		return err
+		return annotate(err, -1, p)
 	}
 
 	b.WriteString("5|")
@@ -57,23 +62,39 @@ func (p *Payroll) marshal(b *strings.Builder) error {
 }
 
 func (p *Payroll) validate() error {
-	if err := validateNRB(p.DebitAccount, "debit account"); err != nil {
-		return err
-	}
-	if err := validateNRB(p.CreditAccount, "credit account"); err != nil {
-		return err
-	}
-	if err := validateRecipientName(p.RecipientName); err != nil {
+	return joinValidationErrors(
+		validateNRB(p.DebitAccount, "DebitAccount"),
+		validateNRB(p.CreditAccount, "CreditAccount"),
+		validateRecipientName(p.RecipientName),
+		validateAddress(p.Address, true),
+		validateAmount(p.Amount, "Amount"),
+		validateTransferMode(p.Mode, ModeInternal, ModeElixir, ModeSORBNET, ModeExpressElixir),
+		validateInternalMode(p.Mode, p.CreditAccount),
+		validateModeAmount(p.Mode, p.Amount, "Amount"),
+		validateTitle(p.Title),
+	)
+}
+
+func (p *Payroll) unmarshal(f []string) error {
+	if err := checkFieldCount(f, 9); err != nil {
 		return err
 	}
-	if err := validateAddress(p.Address, true); err != nil {
+
+	var err error
+	p.DebitAccount = f[1]
+	p.CreditAccount = f[2]
+	p.RecipientName = f[3]
+	p.Address = f[4]
+	if p.Amount, err = parseAmount(f, 5); err != nil {
 		return err
 	}
-	if err := validateTransferMode(p.Mode, ModeInternal, ModeElixir, ModeSORBNET, ModeExpressElixir); err != nil {
+	if p.Mode, err = parseMode(f, 6); err != nil {
 		return err
 	}
-	if err := validateTitle(p.Title); err != nil {
+	p.Title = f[7]
+	if p.Date, err = parseDate(f, 8); err != nil {
 		return err
 	}
+
 	return nil
 }
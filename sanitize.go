@@ -0,0 +1,188 @@
+package sanpltxt
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SanitizeChange records a field value rewritten by sanitization.
+type SanitizeChange struct {
+	TransferIndex int    // position in the package, -1 for a standalone transfer
+	TransferType  int    // transfer type 1-6
+	Field         string // struct field name, e.g. "RecipientName"
+	Original      string
+	Sanitized     string
+}
+
+// transliterations replaces characters that have no usable Unicode
+// decomposition. Candidates that are not allowed in a field are skipped.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D",
+	'þ': "th", 'Þ': "TH", 'ı': "i", 'ł': "l", 'Ł': "L",
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '―': "-", '−': "-",
+	'…': "...", '|': "/", '&': "+",
+	// Quotes and apostrophes are dropped rather than turned into spaces.
+	'"': "", '\'': "", '`': "", '‘': "", '’': "", '‚': "", '“': "", '”': "", '„': "", '«': "", '»': "",
+}
+
+// SanitizeRecipientName rewrites s to fit the recipient name field. See
+// SanitizeTitle for the rules applied.
+func SanitizeRecipientName(s string) string {
+	return sanitize(s, charsRecipientName, maxRecipientName)
+}
+
+// SanitizeAddress rewrites s to fit the address field. See SanitizeTitle for
+// the rules applied.
+func SanitizeAddress(s string) string { return sanitize(s, charsAddress, maxAddress) }
+
+// SanitizeTitle rewrites s to fit the title field. Letters outside the
+// allowed characters are transliterated to ASCII (Polish letters are kept),
+// other forbidden characters are replaced or removed, whitespace is collapsed
+// and the result is truncated to the field limit on a word boundary.
+func SanitizeTitle(s string) string { return sanitize(s, charsTitle, maxTitle) }
+
+// SanitizePayerName rewrites s to fit the payer name field. See SanitizeTitle
+// for the rules applied.
+func SanitizePayerName(s string) string { return sanitize(s, charsPayerName, maxPayerName) }
+
+// SanitizeFormSymbol rewrites s to fit the form symbol field. It is
+// upper-cased first; see SanitizeTitle for the other rules applied.
+func SanitizeFormSymbol(s string) string {
+	return sanitize(strings.ToUpper(s), charsFormSymbol, maxFormSymbol)
+}
+
+// SanitizeObligationID rewrites s to fit the obligation ID field. See
+// SanitizeTitle for the rules applied.
+func SanitizeObligationID(s string) string {
+	return sanitize(s, charsObligationID, maxObligationID)
+}
+
+// SanitizeInvoiceNumber rewrites s to fit the invoice number field. See
+// SanitizeTitle for the rules applied.
+func SanitizeInvoiceNumber(s string) string {
+	return sanitize(s, charsInvoice, maxInvoiceNumber)
+}
+
+// SanitizeFreeText rewrites s to fit the split payment free text field. See
+// SanitizeTitle for the rules applied.
+func SanitizeFreeText(s string) string { return sanitize(s, charsFreeText, maxFreeText) }
+
+// SanitizeTransfer returns a copy of t with its free-text fields sanitized
+// and the list of fields that changed. Transfers of unknown types are
+// returned unchanged.
+func SanitizeTransfer(t Transfer) (Transfer, []SanitizeChange) {
+	var changes []SanitizeChange
+	field := func(name string, v *string, fn func(string) string) {
+		if s := fn(*v); s != *v {
+			changes = append(changes, SanitizeChange{
+				TransferIndex: -1,
+				TransferType:  transferType(t),
+				Field:         name,
+				Original:      *v,
+				Sanitized:     s,
+			})
+			*v = s
+		}
+	}
+
+	switch t := t.(type) {
+	case *Standard:
+		c := *t
+		field("RecipientName", &c.RecipientName, SanitizeRecipientName)
+		field("Address", &c.Address, SanitizeAddress)
+		field("Title", &c.Title, SanitizeTitle)
+		return &c, changes
+	case *ZUS:
+		c := *t
+		field("RecipientName", &c.RecipientName, SanitizeRecipientName)
+		field("Address", &c.Address, SanitizeAddress)
+		field("Title", &c.Title, SanitizeTitle)
+		return &c, changes
+	case *Tax:
+		c := *t
+		field("RecipientName", &c.RecipientName, SanitizeRecipientName)
+		field("Address", &c.Address, SanitizeAddress)
+		field("PayerName", &c.PayerName, SanitizePayerName)
+		field("FormSymbol", &c.FormSymbol, SanitizeFormSymbol)
+		field("ObligationID", &c.ObligationID, SanitizeObligationID)
+		return &c, changes
+	case *Payroll:
+		c := *t
+		field("RecipientName", &c.RecipientName, SanitizeRecipientName)
+		field("Address", &c.Address, SanitizeAddress)
+		field("Title", &c.Title, SanitizeTitle)
+		return &c, changes
+	case *SplitPayment:
+		c := *t
+		field("RecipientName", &c.RecipientName, SanitizeRecipientName)
+		field("Address", &c.Address, SanitizeAddress)
+		field("InvoiceNumber", &c.InvoiceNumber, SanitizeInvoiceNumber)
+		field("FreeText", &c.FreeText, SanitizeFreeText)
+		return &c, changes
+	}
+	return t, nil
+}
+
+func sanitize(s string, allowed map[rune]struct{}, limit int) string {
+	var b strings.Builder
+	for _, r := range norm.NFC.String(s) {
+		if _, ok := allowed[r]; ok {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(replaceRune(r, allowed))
+	}
+
+	sep := " "
+	if _, ok := allowed[' ']; !ok {
+		sep = ""
+	}
+	return truncateWords(strings.Join(strings.Fields(b.String()), sep), limit)
+}
+
+// replaceRune returns an allowed replacement for r: a transliteration, the
+// base letter of a decomposable character, or a space.
+func replaceRune(r rune, allowed map[rune]struct{}) string {
+	if repl, ok := transliterations[r]; ok && containsOnly(repl, allowed) {
+		return repl
+	}
+
+	var base strings.Builder
+	for _, d := range norm.NFD.String(string(r)) {
+		if unicode.Is(unicode.Mn, d) {
+			continue
+		}
+		if _, ok := allowed[d]; !ok {
+			base.Reset()
+			break
+		}
+		base.WriteRune(d)
+	}
+	if base.Len() > 0 {
+		return base.String()
+	}
+
+	return " " // collapsed or removed later
+}
+
+// truncateWords shortens s to at most limit characters, cutting at the last
+// space that fits if one exists.
+func truncateWords(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	cut := runes[:limit]
+	if runes[limit] != ' ' {
+		for i := len(cut) - 1; i > 0; i-- {
+			if cut[i] == ' ' {
+				cut = cut[:i]
+				break
+			}
+		}
+	}
+	return strings.TrimRight(string(cut), " ")
+}
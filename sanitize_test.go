@@ -0,0 +1,72 @@
+package sanpltxt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{"quotes", sanpltxt.SanitizeRecipientName, `Firma "Kowalski" & Syn`, "Firma Kowalski & Syn"},
+		{"umlauts", sanpltxt.SanitizeRecipientName, "Müller GmbH Straße", "Muller GmbH Strasse"},
+		{"hacek", sanpltxt.SanitizeAddress, "Dvořák\tČeské Budějovice", "Dvorak Ceske Budejovice"},
+		{"polish kept", sanpltxt.SanitizeAddress, "Łódź, ul. Żółta 1", "Łódź, ul. Żółta 1"},
+		{"decomposed polish", sanpltxt.SanitizeAddress, "Lo\u0301dz\u0301", "Lódź"},
+		{"ampersand", sanpltxt.SanitizeAddress, "Kowalski & Syn", "Kowalski Syn"},
+		{"dash and pipe", sanpltxt.SanitizeTitle, "FV 1/2024 — zaliczka | rata 2\n", "FV 1/2024 - zaliczka / rata 2"},
+		{"apostrophe", sanpltxt.SanitizeTitle, "O'Brien’s invoice", "OBriens invoice"},
+		{"form symbol", sanpltxt.SanitizeFormSymbol, "vat-7 k", "VAT-7K"},
+		{"truncate", sanpltxt.SanitizeFreeText, "Faktura za usługi transportowe w marcu", "Faktura za usługi transportowe w"},
+		{"truncate long word", sanpltxt.SanitizeFormSymbol, "ABCDEFGH", "ABCDEF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.fn(tt.in), tt.want)
+		})
+	}
+}
+
+func TestPackage_Marshal_Sanitize(t *testing.T) {
+	original := &sanpltxt.Standard{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100016",
+		RecipientName: "Müller & Co.",
+		Address:       "Berlin, Hauptstraße 5",
+		Amount:        100,
+		Mode:          sanpltxt.ModeElixir,
+		Title:         `Rechnung "2024/1"` + "\r\n" + strings.Repeat("x", 130),
+	}
+
+	_, err := sanpltxt.NewPackage(1, []sanpltxt.Transfer{original}, nil).Marshal()
+	assert.Error(t, err)
+
+	var changes []sanpltxt.SanitizeChange
+	pkg := sanpltxt.NewPackage(1, []sanpltxt.Transfer{original}, &sanpltxt.PackageOptions{
+		Sanitize:   true,
+		OnSanitize: func(c sanpltxt.SanitizeChange) { changes = append(changes, c) },
+	})
+	assert.NoError(t, pkg.Validate())
+
+	got, err := pkg.Marshal()
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(got, "|Muller & Co.|Berlin, Hauptstrasse 5|1|1|Rechnung 2024/1|"))
+
+	assert.Equal(t, len(changes), 3)
+	assert.Equal(t, changes[0].TransferIndex, 0)
+	assert.Equal(t, changes[0].Field, "RecipientName")
+	assert.Equal(t, changes[0].Original, "Müller & Co.")
+	assert.Equal(t, changes[0].Sanitized, "Muller & Co.")
+	assert.Equal(t, changes[2].Field, "Title")
+
+	assert.Equal(t, original.RecipientName, "Müller & Co.") // input is not modified
+}
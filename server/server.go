@@ -0,0 +1,327 @@
+// Package server validates and renders transfer packages over HTTP, so that
+// programs not written in Go can rely on the same format rules.
+//
+// Requests carry a package in the JSON representation described by
+// sanpltxt.JSONSchema, sent as application/json or without a Content-Type.
+// The endpoints are:
+//
+//	POST /validate  check the package and report every problem found
+//	POST /render    return the import file, encoded in Windows-1250
+//	GET  /schema    return the JSON Schema of the request body
+//
+// Both POST endpoints accept the query parameter sanitize=true, which rewrites
+// free-text fields to fit their allowed characters and lengths before
+// validation; see sanpltxt.SanitizeTransfer. /render also accepts filename,
+// the name suggested in Content-Disposition.
+//
+// /validate responds with 200 and a Result whether or not the package is
+// valid. /render responds with 422 and a Result if it is not. Requests that
+// cannot be read respond with 4xx and an object holding an "error" message.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+// DefaultMaxBodySize is the request body limit used if Options.MaxBodySize
+// is zero.
+const DefaultMaxBodySize = 10 << 20
+
+// DefaultFilename is the file name suggested by /render if the request does
+// not name one.
+const DefaultFilename = "transfers.txt"
+
+// Options configures a Server.
+type Options struct {
+	// MaxBodySize limits the size of request bodies in bytes. Larger requests
+	// are rejected with 413.
+	MaxBodySize int64
+
+	// ErrorLog receives errors writing responses, which usually mean the
+	// client went away. If nil, the log package's standard logger is used.
+	ErrorLog *log.Logger
+}
+
+// Server is an http.Handler serving the endpoints described in the package
+// documentation.
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+}
+
+// New returns a Server. opts may be nil.
+func New(opts *Options) *Server {
+	s := &Server{mux: http.NewServeMux()}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.MaxBodySize <= 0 {
+		s.opts.MaxBodySize = DefaultMaxBodySize
+	}
+	s.mux.HandleFunc("POST /validate", s.validate)
+	s.mux.HandleFunc("POST /render", s.render)
+	s.mux.HandleFunc("GET /schema", s.schema)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Result is the response body of /validate, and of /render for an invalid
+// package.
+type Result struct {
+	Valid     bool     `json:"valid"`
+	Transfers int      `json:"transfers"`
+	Errors    []Error  `json:"errors"`
+	Sanitized []Change `json:"sanitized,omitempty"`
+}
+
+// Error is a validation error. It carries the fields of
+// sanpltxt.ValidationError, with Field named as in the JSON representation
+// and Pointer locating the value in the request body as a JSON Pointer (RFC
+// 6901).
+type Error struct {
+	Transfer     *int          `json:"transfer,omitempty"` // 0-based index, absent for package-level errors
+	TransferType int           `json:"transfer_type,omitempty"`
+	Field        string        `json:"field,omitempty"`
+	Pointer      string        `json:"pointer"`
+	Rule         sanpltxt.Rule `json:"rule"`
+	Value        string        `json:"value,omitempty"`
+	Min          int           `json:"min,omitempty"`
+	Max          int           `json:"max,omitempty"`
+	Length       int           `json:"length,omitempty"`
+	Message      string        `json:"message"`
+}
+
+// Change is a field value rewritten by sanitization.
+type Change struct {
+	Transfer  int    `json:"transfer"`
+	Field     string `json:"field"`
+	Pointer   string `json:"pointer"`
+	Original  string `json:"original"`
+	Sanitized string `json:"sanitized"`
+}
+
+func (s *Server) validate(w http.ResponseWriter, r *http.Request) {
+	pkg, sanitize, ok := s.readPackage(w, r)
+	if !ok {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, check(pkg, sanitize))
+}
+
+func (s *Server) render(w http.ResponseWriter, r *http.Request) {
+	pkg, sanitize, ok := s.readPackage(w, r)
+	if !ok {
+		return
+	}
+	filename := DefaultFilename
+	if name := r.URL.Query().Get("filename"); name != "" {
+		filename = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	}
+
+	res := check(pkg, sanitize)
+	if !res.Valid {
+		s.writeJSON(w, http.StatusUnprocessableEntity, res)
+		return
+	}
+	b, err := pkg.MarshalBytes()
+	if err != nil {
+		// Validate runs the same checks as MarshalBytes, so what remains
+		// is encoding to Windows-1250.
+		s.writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=windows-1250")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	s.write(w, b)
+}
+
+func (s *Server) schema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	s.write(w, sanpltxt.JSONSchema())
+}
+
+// readPackage decodes the package in the request body and reports whether
+// sanitization was requested. If it fails, it writes the error response and
+// returns false.
+func (s *Server) readPackage(w http.ResponseWriter, r *http.Request) (pkg *sanpltxt.Package, sanitize, ok bool) {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mt, _, _ := mime.ParseMediaType(ct); mt != "application/json" {
+			s.writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("content type %q, want application/json", ct))
+			return nil, false, false
+		}
+	}
+	sanitize, err := parseBool(r.URL.Query().Get("sanitize"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("sanitize: %w", err))
+		return nil, false, false
+	}
+
+	var body sanpltxt.Package
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.opts.MaxBodySize))
+	err = dec.Decode(&body)
+	if err == nil {
+		if _, err = dec.Token(); err == io.EOF {
+			err = nil
+		} else if err == nil {
+			err = errors.New("unexpected data after the package")
+		}
+	}
+	if err != nil {
+		var merr *http.MaxBytesError
+		if errors.As(err, &merr) {
+			s.writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", merr.Limit))
+			return nil, false, false
+		}
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("decoding package: %w", err))
+		return nil, false, false
+	}
+	return sanpltxt.NewPackage(body.Type(), body.Transfers(), &sanpltxt.PackageOptions{Sanitize: sanitize}), sanitize, true
+}
+
+func parseBool(s string) (bool, error) {
+	if s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// check validates pkg and lists the changes sanitization makes, if enabled.
+func check(pkg *sanpltxt.Package, sanitize bool) *Result {
+	transfers := pkg.Transfers()
+	res := &Result{Transfers: len(transfers), Errors: []Error{}}
+
+	var verrs sanpltxt.ValidationErrors
+	if err := pkg.Validate(); errors.As(err, &verrs) {
+		for _, e := range verrs {
+			res.Errors = append(res.Errors, convertError(e, transfers))
+		}
+	} else if err != nil {
+		res.Errors = append(res.Errors, Error{Rule: sanpltxt.RuleInvalidValue, Message: err.Error()})
+	}
+	res.Valid = len(res.Errors) == 0
+
+	if sanitize {
+		for i, t := range transfers {
+			_, changes := sanpltxt.SanitizeTransfer(t)
+			for _, c := range changes {
+				field := jsonField(t, c.Field)
+				res.Sanitized = append(res.Sanitized, Change{
+					Transfer:  i,
+					Field:     field,
+					Pointer:   transferPointer(i, field),
+					Original:  c.Original,
+					Sanitized: c.Sanitized,
+				})
+			}
+		}
+	}
+	return res
+}
+
+func convertError(e *sanpltxt.ValidationError, transfers []sanpltxt.Transfer) Error {
+	plain := *e
+	plain.TransferIndex = -1 // the index is reported separately
+	out := Error{
+		TransferType: e.TransferType,
+		Rule:         e.Rule,
+		Value:        e.Value,
+		Min:          e.Min,
+		Max:          e.Max,
+		Length:       e.Length,
+		Message:      plain.Error(),
+	}
+	switch {
+	case e.TransferIndex >= 0 && e.TransferIndex < len(transfers):
+		i := e.TransferIndex
+		out.Transfer = &i
+		out.Field = jsonField(transfers[i], e.Field)
+		out.Pointer = transferPointer(i, out.Field)
+	case e.Rule == sanpltxt.RulePackageType:
+		out.Pointer = "/type"
+	}
+	return out
+}
+
+// jsonField returns the JSON name of the struct field of t named field, or
+// field if t has no such field.
+func jsonField(t sanpltxt.Transfer, field string) string {
+	typ := reflect.TypeOf(t)
+	if typ == nil || field == "" {
+		return field
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return field
+	}
+	f, ok := typ.FieldByName(field)
+	if !ok {
+		return field
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field
+	}
+	return name
+}
+
+func transferPointer(i int, field string) string {
+	p := "/transfers/" + strconv.Itoa(i)
+	if field != "" {
+		p += "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(field)
+	}
+	return p
+}
+
+// write writes the response body b. The status line and headers are already
+// sent, so a failure can only be logged.
+func (s *Server) write(w http.ResponseWriter, b []byte) {
+	if _, err := w.Write(b); err != nil {
+		s.logf("writing response: %v", err)
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		s.logf("encoding response: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	s.write(w, append(b, '\n'))
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
+	s.writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.opts.ErrorLog != nil {
+		s.opts.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
@@ -0,0 +1,173 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+	"github.com/amwolff/sanpltxt/server"
+)
+
+const validPackage = `{
+	"type": 1,
+	"transfers": [
+		{
+			"type": "standard",
+			"debit_account": "51109010430000000100111111",
+			"credit_account": "50102055581111103350100016",
+			"recipient_name": "Jan Kowalski",
+			"address": "Łódź ul. Kaliska 123",
+			"amount": "123.12",
+			"mode": 1,
+			"title": "zasilenie konta",
+			"date": "2020-09-01"
+		}
+	]
+}`
+
+func do(t *testing.T, s *server.Server, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestValidate(t *testing.T) {
+	s := server.New(nil)
+
+	rec := do(t, s, "POST", "/validate", validPackage)
+	assert.Equal(t, rec.Code, http.StatusOK)
+	var res server.Result
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.True(t, res.Valid)
+	assert.Equal(t, res.Transfers, 1)
+	assert.Equal(t, len(res.Errors), 0)
+
+	invalid := strings.NewReplacer(`"type": 1`, `"type": 3`, "50102055581111103350100016", "50102055581111103350100017").Replace(validPackage)
+	rec = do(t, s, "POST", "/validate", invalid)
+	assert.Equal(t, rec.Code, http.StatusOK)
+	res = server.Result{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.False(t, res.Valid)
+	assert.Equal(t, len(res.Errors), 2)
+
+	assert.Nil(t, res.Errors[0].Transfer)
+	assert.Equal(t, res.Errors[0].Pointer, "/type")
+	assert.Equal(t, res.Errors[0].Rule, sanpltxt.RulePackageType)
+
+	assert.Equal(t, *res.Errors[1].Transfer, 0)
+	assert.Equal(t, res.Errors[1].TransferType, 1)
+	assert.Equal(t, res.Errors[1].Field, "credit_account")
+	assert.Equal(t, res.Errors[1].Pointer, "/transfers/0/credit_account")
+	assert.Equal(t, res.Errors[1].Rule, sanpltxt.RuleChecksum)
+	assert.Equal(t, res.Errors[1].Value, "50102055581111103350100017")
+	assert.False(t, strings.HasPrefix(res.Errors[1].Message, "transfer"))
+}
+
+func TestValidate_Sanitize(t *testing.T) {
+	s := server.New(nil)
+	body := strings.Replace(validPackage, "zasilenie konta", "zasilenie konta – „bonus”", 1)
+
+	rec := do(t, s, "POST", "/validate", body)
+	var res server.Result
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.False(t, res.Valid)
+
+	rec = do(t, s, "POST", "/validate?sanitize=true", body)
+	res = server.Result{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.True(t, res.Valid)
+	assert.DeepEqual(t, res.Sanitized, []server.Change{{
+		Transfer:  0,
+		Field:     "title",
+		Pointer:   "/transfers/0/title",
+		Original:  "zasilenie konta – „bonus”",
+		Sanitized: "zasilenie konta - bonus",
+	}})
+}
+
+func TestRender(t *testing.T) {
+	s := server.New(nil)
+
+	rec := do(t, s, "POST", "/render?filename=przelewy%20wrzesień.txt", validPackage)
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "text/plain; charset=windows-1250")
+	assert.Equal(t, rec.Header().Get("Content-Disposition"), "attachment; filename*=utf-8''przelewy%20wrzesie%C5%84.txt")
+	want, err := sanpltxt.ToWindows1250("4120414|1\n" +
+		"1|51109010430000000100111111|50102055581111103350100016|Jan Kowalski|Łódź ul. Kaliska 123|123,12|1|zasilenie konta|01-09-2020||\n")
+	assert.NoError(t, err)
+	assert.Equal(t, rec.Body.String(), string(want))
+
+	rec = do(t, s, "POST", "/render", validPackage)
+	assert.Equal(t, rec.Header().Get("Content-Disposition"), `attachment; filename=transfers.txt`)
+
+	rec = do(t, s, "POST", "/render", strings.Replace(validPackage, `"type": 1`, `"type": 2`, 1))
+	assert.Equal(t, rec.Code, http.StatusUnprocessableEntity)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "application/json")
+	var res server.Result
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.False(t, res.Valid)
+	assert.Equal(t, res.Errors[0].Rule, sanpltxt.RulePackageType)
+}
+
+func TestRequestErrors(t *testing.T) {
+	s := server.New(&server.Options{MaxBodySize: 1000})
+
+	tests := []struct {
+		name, method, target, body string
+		code                       int
+	}{
+		{"malformed", "POST", "/validate", `{"type": 1,`, http.StatusBadRequest},
+		{"unknown transfer type", "POST", "/render", `{"type": 1, "transfers": [{"type": "wire"}]}`, http.StatusBadRequest},
+		{"trailing data", "POST", "/validate", validPackage + "{}", http.StatusBadRequest},
+		{"bad sanitize", "POST", "/validate?sanitize=maybe", validPackage, http.StatusBadRequest},
+		{"too large", "POST", "/validate", strings.Replace(validPackage, "[", "["+strings.Repeat(" ", 1000), 1), http.StatusRequestEntityTooLarge},
+		{"too large trailer", "POST", "/validate", validPackage + strings.Repeat(" ", 1000), http.StatusRequestEntityTooLarge},
+		{"method", "GET", "/validate", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, s, tt.method, tt.target, tt.body)
+			assert.Equal(t, rec.Code, tt.code)
+		})
+	}
+
+	req := httptest.NewRequest("POST", "/validate", strings.NewReader(validPackage))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	assert.Equal(t, rec.Code, http.StatusUnsupportedMediaType)
+	var body struct{ Error string }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, body.Error, `content type "text/csv", want application/json`)
+}
+
+func TestSchema(t *testing.T) {
+	rec := do(t, server.New(nil), "GET", "/schema", "")
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "application/schema+json")
+	assert.Equal(t, rec.Body.String(), string(sanpltxt.JSONSchema()))
+}
+
+type failingWriter struct{ *httptest.ResponseRecorder }
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestWriteErrorsLogged(t *testing.T) {
+	var buf bytes.Buffer
+	s := server.New(&server.Options{ErrorLog: log.New(&buf, "", 0)})
+
+	req := httptest.NewRequest("GET", "/schema", nil)
+	s.ServeHTTP(failingWriter{httptest.NewRecorder()}, req)
+	assert.Equal(t, buf.String(), "writing response: connection reset\n")
+}
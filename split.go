@@ -0,0 +1,101 @@
+package sanpltxt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// SplitKey selects the transfer attributes Package.Split groups by. Keys can
+// be combined with |.
+type SplitKey int
+
+const (
+	SplitByDebitAccount SplitKey = 1 << iota
+	SplitByDate                  // execution date
+	SplitByType                  // transfer type 1-6
+)
+
+// SplitOptions controls Package.Split. Zero values disable the corresponding
+// limit.
+type SplitOptions struct {
+	MaxTransfers int    // maximum number of transfers per package
+	MaxAmount    Amount // maximum sum of amounts per package
+	GroupBy      SplitKey
+}
+
+// Split breaks the package into packages of the same type and options. Each
+// group of transfers sharing the GroupBy attributes is placed in separate
+// packages, which are then cut so none exceeds MaxTransfers or MaxAmount.
+// Groups appear in the order of their first transfer and transfers keep their
+// relative order. Split payments count with their gross amount.
+//
+// A package with no transfers is returned as a single empty package. Split
+// fails if a single transfer exceeds MaxAmount.
+func (p *Package) Split(opts SplitOptions) ([]*Package, error) {
+	if opts.MaxTransfers < 0 {
+		return nil, errors.New("max transfers must not be negative")
+	}
+	if opts.MaxAmount < 0 {
+		return nil, errors.New("max amount must not be negative")
+	}
+	if len(p.transfers) == 0 {
+		return []*Package{p.withTransfers(nil)}, nil
+	}
+
+	var (
+		keys   []string
+		groups = make(map[string][]Transfer)
+	)
+	for _, t := range p.transfers {
+		k := opts.GroupBy.key(infoOf(t))
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], t)
+	}
+
+	var packages []*Package
+	for _, k := range keys {
+		var (
+			chunk []Transfer
+			sum   Amount
+		)
+		for _, t := range groups[k] {
+			a := infoOf(t).amount
+			if opts.MaxAmount > 0 && a > opts.MaxAmount {
+				return nil, fmt.Errorf("transfer amount %s exceeds max amount %s", a, opts.MaxAmount)
+			}
+			full := opts.MaxTransfers > 0 && len(chunk) == opts.MaxTransfers
+			if opts.MaxAmount > 0 && sum+a > opts.MaxAmount {
+				full = true
+			}
+			if full {
+				packages = append(packages, p.withTransfers(chunk))
+				chunk, sum = nil, 0
+			}
+			chunk = append(chunk, t)
+			sum += a
+		}
+		packages = append(packages, p.withTransfers(chunk))
+	}
+	return packages, nil
+}
+
+func (p *Package) withTransfers(transfers []Transfer) *Package {
+	return &Package{typ: p.typ, transfers: transfers, options: p.options}
+}
+
+func (k SplitKey) key(info transferInfo) string {
+	var s string
+	if k&SplitByDebitAccount != 0 {
+		s += info.debit + "|"
+	}
+	if k&SplitByDate != 0 {
+		s += dateKey(info.date) + "|"
+	}
+	if k&SplitByType != 0 {
+		s += strconv.Itoa(info.typ) + "|"
+	}
+	return s
+}
@@ -1,23 +1,25 @@
 package sanpltxt
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
 
 // SplitPayment is a Type 6 transfer (split VAT payment).
 type SplitPayment struct {
-	DebitAccount  string
-	CreditAccount string
-	RecipientName string
-	Address       string
-	GrossAmount   Amount
-	Mode          TransferMode
-	VATAmount     Amount
-	RecipientNIP  string
-	InvoiceNumber string
-	FreeText      string
-	Date          *time.Time
+	DebitAccount  string       `json:"debit_account"`
+	CreditAccount string       `json:"credit_account"`
+	RecipientName string       `json:"recipient_name"`
+	Address       string       `json:"address,omitempty"`
+	GrossAmount   Amount       `json:"gross_amount"`
+	Mode          TransferMode `json:"mode"`
+	VATAmount     Amount       `json:"vat_amount"`
+	RecipientNIP  string       `json:"recipient_nip"` // normalized with NormalizeNIP
+	InvoiceNumber string       `json:"invoice_number"`
+	FreeText      string       `json:"free_text,omitempty"`
+	Date          *time.Time   `json:"date,omitempty"`
 }
 
 var _ Transfer = (*SplitPayment)(nil)
@@ -25,15 +27,20 @@ var _ Transfer = (*SplitPayment)(nil)
 // Marshal returns the transfer in Santander format.
 func (s *SplitPayment) Marshal() (string, error) {
 	var b strings.Builder
-	if err := s.marshal(&b); err != nil {
+	if err := marshalRecord(&b, s); err != nil {
 		return "", err
 	}
 	return b.String(), nil
 }
 
+// RecipientBank returns the bank holding CreditAccount. See BankInfo.
+func (s *SplitPayment) RecipientBank() (Bank, bool) {
+	return BankInfo(s.CreditAccount)
+}
+
 func (s *SplitPayment) marshal(b *strings.Builder) error {
 	if err := s.validate(); err != nil {
-		return err
+		return annotate(err, -1, s)
 	}
 
 	b.WriteString("6|")
@@ -63,7 +70,7 @@ func (s *SplitPayment) formatTitle(b *strings.Builder) {
 	b.WriteString("/VAT/")
 	b.WriteString(s.VATAmount.String())
 	b.WriteString("/IDC/")
-	b.WriteString(s.RecipientNIP)
+	b.WriteString(NormalizeNIP(s.RecipientNIP))
 	b.WriteString("/INV/")
 	b.WriteString(s.InvoiceNumber)
 	if s.FreeText != "" {
@@ -73,29 +80,73 @@ func (s *SplitPayment) formatTitle(b *strings.Builder) {
 }
 
 func (s *SplitPayment) validate() error {
-	if err := validateNRB(s.DebitAccount, "debit account"); err != nil {
+	return joinValidationErrors(
+		validateNRB(s.DebitAccount, "DebitAccount"),
+		validateNRB(s.CreditAccount, "CreditAccount"),
+		validateRecipientName(s.RecipientName),
+		validateAddress(s.Address, false),
+		validateAmount(s.GrossAmount, "GrossAmount"),
+		validateTransferMode(s.Mode, ModeInternal, ModeElixir, ModeSORBNET, ModeExpressElixir),
+		validateInternalMode(s.Mode, s.CreditAccount),
+		validateModeAmount(s.Mode, s.GrossAmount, "GrossAmount"),
+		validateVATAmount(s.VATAmount, s.GrossAmount),
+		validateNIP(s.RecipientNIP, "RecipientNIP"),
+		validateInvoiceNumber(s.InvoiceNumber),
+		validateFreeText(s.FreeText),
+	)
+}
+
+func (s *SplitPayment) unmarshal(f []string) error {
+	if err := checkFieldCount(f, 9); err != nil {
 		return err
 	}
-	if err := validateNRB(s.CreditAccount, "credit account"); err != nil {
+
+	var err error
+	s.DebitAccount = f[1]
+	s.CreditAccount = f[2]
+	s.RecipientName = f[3]
+	s.Address = f[4]
+	if s.GrossAmount, err = parseAmount(f, 5); err != nil {
 		return err
 	}
-	if err := validateRecipientName(s.RecipientName); err != nil {
+	if s.Mode, err = parseMode(f, 6); err != nil {
 		return err
 	}
-	if err := validateAddress(s.Address, false); err != nil {
-		return err
+	if err = s.parseTitle(f[7]); err != nil {
+		return fieldError(7, err)
 	}
-	if err := validateTransferMode(s.Mode, ModeInternal, ModeElixir, ModeSORBNET, ModeExpressElixir); err != nil {
+	if s.Date, err = parseDate(f, 8); err != nil {
 		return err
 	}
-	if err := validateNIP(s.RecipientNIP); err != nil {
-		return err
+
+	return nil
+}
+
+// parseTitle decodes a /VAT/<amount>/IDC/<NIP>/INV/<invoice>[/TXT/<text>] title.
+// The first /TXT/ ends the invoice number, so the free text keeps any later
+// ones; validation rejects invoice numbers that would make this ambiguous.
+func (s *SplitPayment) parseTitle(title string) error {
+	rest, ok := strings.CutPrefix(title, "/VAT/")
+	if !ok {
+		return errors.New("split payment title must start with /VAT/")
 	}
-	if err := validateInvoiceNumber(s.InvoiceNumber); err != nil {
-		return err
+	vat, rest, ok := strings.Cut(rest, "/IDC/")
+	if !ok {
+		return errors.New("split payment title is missing /IDC/")
 	}
-	if err := validateFreeText(s.FreeText); err != nil {
-		return err
+	nip, rest, ok := strings.Cut(rest, "/INV/")
+	if !ok {
+		return errors.New("split payment title is missing /INV/")
+	}
+	invoice, text, _ := strings.Cut(rest, "/TXT/")
+
+	a, err := parseAmount([]string{vat}, 0)
+	if err != nil {
+		return fmt.Errorf("invalid VAT amount %q", vat)
 	}
+	s.VATAmount = a
+	s.RecipientNIP = nip
+	s.InvoiceNumber = invoice
+	s.FreeText = text
 	return nil
 }
@@ -0,0 +1,65 @@
+package sanpltxt_test
+
+import (
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func TestPackage_Split(t *testing.T) {
+	pkg := balancePackage()
+	all := pkg.Transfers()
+
+	tests := []struct {
+		name string
+		opts sanpltxt.SplitOptions
+		want [][]int // indexes into all
+	}{
+		{"no limits", sanpltxt.SplitOptions{}, [][]int{{0, 1, 2, 3, 4}}},
+		{"max transfers", sanpltxt.SplitOptions{MaxTransfers: 2}, [][]int{{0, 1}, {2, 3}, {4}}},
+		{"max amount", sanpltxt.SplitOptions{MaxAmount: 60000}, [][]int{{0}, {1, 2, 3}, {4}}},
+		{"debit account", sanpltxt.SplitOptions{GroupBy: sanpltxt.SplitByDebitAccount}, [][]int{{0, 1, 2, 3}, {4}}},
+		{"date", sanpltxt.SplitOptions{GroupBy: sanpltxt.SplitByDate}, [][]int{{0, 4}, {1, 3}, {2}}},
+		{
+			"debit account and date",
+			sanpltxt.SplitOptions{GroupBy: sanpltxt.SplitByDebitAccount | sanpltxt.SplitByDate, MaxTransfers: 1},
+			[][]int{{0}, {1}, {3}, {2}, {4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pkg.Split(tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, len(got), len(tt.want))
+			for i, p := range got {
+				assert.Equal(t, p.Type(), pkg.Type())
+				var want []sanpltxt.Transfer
+				for _, j := range tt.want[i] {
+					want = append(want, all[j])
+				}
+				assert.DeepEqual(t, p.Transfers(), want)
+
+				_, err := p.Marshal()
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPackage_Split_Errors(t *testing.T) {
+	pkg := balancePackage()
+
+	_, err := pkg.Split(sanpltxt.SplitOptions{MaxAmount: 40000})
+	assert.Error(t, err)
+
+	_, err = pkg.Split(sanpltxt.SplitOptions{MaxTransfers: -1})
+	assert.Error(t, err)
+
+	got, err := sanpltxt.NewPackage(2, nil, nil).Split(sanpltxt.SplitOptions{MaxTransfers: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].Type(), 2)
+}
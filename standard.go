@@ -7,15 +7,15 @@ import (
 
 // Standard is a Type 1 transfer (external account transfer).
 type Standard struct {
-	DebitAccount  string
-	CreditAccount string
-	RecipientName string
-	Address       string
-	Amount        Amount
-	Mode          TransferMode
-	Title         string
-	Date          *time.Time
-	NIP           string
+	DebitAccount  string       `json:"debit_account"`
+	CreditAccount string       `json:"credit_account"`
+	RecipientName string       `json:"recipient_name"`
+	Address       string       `json:"address"`
+	Amount        Amount       `json:"amount"`
+	Mode          TransferMode `json:"mode"`
+	Title         string       `json:"title"`
+	Date          *time.Time   `json:"date,omitempty"`
+	NIP           string       `json:"nip,omitempty"` // normalized with NormalizeNIP
 }
 
 var _ Transfer = (*Standard)(nil)
@@ -23,15 +23,20 @@ var _ Transfer = (*Standard)(nil)
 // Marshal returns the transfer in Santander format.
 func (s *Standard) Marshal() (string, error) {
 	var b strings.Builder
-	if err := s.marshal(&b); err != nil {
+	if err := marshalRecord(&b, s); err != nil {
 		return "", err
 	}
 	return b.String(), nil
 }
 
+// RecipientBank returns the bank holding CreditAccount. See BankInfo.
+func (s *Standard) RecipientBank() (Bank, bool) {
+	return BankInfo(s.CreditAccount)
+}
+
 func (s *Standard) marshal(b *strings.Builder) error {
 	if err := s.validate(); err != nil {
-		return err
+		return annotate(err, -1, s)
 	}
 
 	b.WriteString("1|")
@@ -53,35 +58,51 @@ func (s *Standard) marshal(b *strings.Builder) error {
 		b.WriteString(s.Date.Format(dateFormat))
 	}
 	b.WriteString("|")
-	b.WriteString(s.NIP)
+	b.WriteString(NormalizeNIP(s.NIP))
 	b.WriteString("|")
 
 	return nil
 }
 
 func (s *Standard) validate() error {
-	if err := validateNRB(s.DebitAccount, "debit account"); err != nil {
-		return err
+	errs := []error{
+		validateNRB(s.DebitAccount, "DebitAccount"),
+		validateNRB(s.CreditAccount, "CreditAccount"),
+		validateRecipientName(s.RecipientName),
+		validateAddress(s.Address, true),
+		validateAmount(s.Amount, "Amount"),
+		validateTransferMode(s.Mode, ModeInternal, ModeElixir, ModeSORBNET, ModeExpressElixir),
+		validateInternalMode(s.Mode, s.CreditAccount),
+		validateModeAmount(s.Mode, s.Amount, "Amount"),
+		validateTitle(s.Title),
 	}
-	if err := validateNRB(s.CreditAccount, "credit account"); err != nil {
-		return err
+	if s.NIP != "" {
+		errs = append(errs, validateNIP(s.NIP, "NIP"))
 	}
-	if err := validateRecipientName(s.RecipientName); err != nil {
+	return joinValidationErrors(errs...)
+}
+
+func (s *Standard) unmarshal(f []string) error {
+	if err := checkFieldCount(f, 10); err != nil {
 		return err
 	}
-	if err := validateAddress(s.Address, true); err != nil {
+
+	var err error
+	s.DebitAccount = f[1]
+	s.CreditAccount = f[2]
+	s.RecipientName = f[3]
+	s.Address = f[4]
+	if s.Amount, err = parseAmount(f, 5); err != nil {
 		return err
 	}
-	if err := validateTransferMode(s.Mode, ModeInternal, ModeElixir, ModeSORBNET, ModeExpressElixir); err != nil {
+	if s.Mode, err = parseMode(f, 6); err != nil {
 		return err
 	}
-	if err := validateTitle(s.Title); err != nil {
+	s.Title = f[7]
+	if s.Date, err = parseDate(f, 8); err != nil {
 		return err
 	}
-	if s.NIP != "" {
-		if err := validateNIP(s.NIP); err != nil {
-			return err
-		}
-	}
+	s.NIP = f[9]
+
 	return nil
 }
@@ -0,0 +1,147 @@
+package sanpltxt
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Totals is the number of transfers and the sum of their amounts. For split
+// payments the gross amount is counted.
+type Totals struct {
+	Count  int
+	Amount Amount
+}
+
+func (t *Totals) add(a Amount) {
+	t.Count++
+	t.Amount += a
+}
+
+// Summary holds the control totals of a package.
+type Summary struct {
+	Totals
+	ByType         map[int]Totals          // transfer type 1-6
+	ByDebitAccount map[string]Totals       // NRB
+	ByMode         map[TransferMode]Totals // ModeElixir for ZUS and tax transfers
+	ByDate         map[string]Totals       // YYYY-MM-DD, "" for immediate execution
+}
+
+// Summary returns the control totals of the package: the number of transfers
+// and the sum of their amounts overall and per transfer type, debit account,
+// transfer mode and execution date.
+func (p *Package) Summary() Summary {
+	s := Summary{
+		ByType:         make(map[int]Totals),
+		ByDebitAccount: make(map[string]Totals),
+		ByMode:         make(map[TransferMode]Totals),
+		ByDate:         make(map[string]Totals),
+	}
+	for _, t := range p.transfers {
+		info := infoOf(t)
+		s.add(info.amount)
+		addTo(s.ByType, info.typ, info.amount)
+		addTo(s.ByDebitAccount, info.debit, info.amount)
+		addTo(s.ByMode, info.mode, info.amount)
+		addTo(s.ByDate, dateKey(info.date), info.amount)
+	}
+	return s
+}
+
+func addTo[K comparable](m map[K]Totals, k K, a Amount) {
+	t := m[k]
+	t.add(a)
+	m[k] = t
+}
+
+var typeNames = map[int]string{
+	1: "standard",
+	2: "ZUS",
+	3: "tax office",
+	4: "other tax",
+	5: "payroll",
+	6: "split payment",
+}
+
+// summaryRow is one line of a rendered summary.
+type summaryRow struct {
+	group, key, label string
+	Totals
+}
+
+func (s Summary) rows() []summaryRow {
+	rows := []summaryRow{{group: "total", Totals: s.Totals}}
+	for _, k := range slices.Sorted(maps.Keys(s.ByType)) {
+		rows = append(rows, summaryRow{"type", strconv.Itoa(k), typeNames[k], s.ByType[k]})
+	}
+	for _, k := range slices.Sorted(maps.Keys(s.ByDebitAccount)) {
+		rows = append(rows, summaryRow{"debit_account", k, k, s.ByDebitAccount[k]})
+	}
+	for _, k := range slices.Sorted(maps.Keys(s.ByMode)) {
+		rows = append(rows, summaryRow{"mode", k.String(), k.Name(), s.ByMode[k]})
+	}
+	for _, k := range slices.Sorted(maps.Keys(s.ByDate)) {
+		label := k
+		if k == "" {
+			label = "immediate"
+		}
+		rows = append(rows, summaryRow{"date", k, label, s.ByDate[k]})
+	}
+	return rows
+}
+
+// WriteText writes the summary as a plain-text report with left-aligned labels
+// and right-aligned count and amount columns.
+func (s Summary) WriteText(w io.Writer) error {
+	headings := map[string]string{
+		"type":          "By transfer type",
+		"debit_account": "By debit account",
+		"mode":          "By transfer mode",
+		"date":          "By execution date",
+	}
+
+	rows := s.rows()
+	rows[0].label = "Total"
+	for i := range rows[1:] {
+		r := &rows[i+1]
+		if r.group == "type" || r.group == "mode" {
+			r.label = r.key + " " + r.label
+		}
+		r.label = "  " + r.label
+	}
+
+	var labelWidth, countWidth, amountWidth int
+	for _, r := range rows {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(r.label))
+		countWidth = max(countWidth, len(strconv.Itoa(r.Count)))
+		amountWidth = max(amountWidth, len(r.Amount.String()))
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, r := range rows {
+		if i > 0 && r.group != rows[i-1].group {
+			fmt.Fprintf(bw, "\n%s\n", headings[r.group])
+		}
+		fmt.Fprintf(bw, "%-*s  %*d  %*s\n", labelWidth, r.label, countWidth, r.Count, amountWidth, r.Amount)
+	}
+	return bw.Flush()
+}
+
+// WriteCSV writes the summary as CSV with the columns group, key, count and
+// amount. The first row after the header holds the package total; amounts are
+// formatted as by Amount.MarshalText.
+func (s Summary) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"group", "key", "count", "amount"})
+	for _, r := range s.rows() {
+		amount, _ := r.Amount.MarshalText()
+		_ = cw.Write([]string{r.group, r.key, strconv.Itoa(r.Count), string(amount)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
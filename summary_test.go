@@ -0,0 +1,108 @@
+package sanpltxt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func summaryPackage() *sanpltxt.Package {
+	return sanpltxt.NewPackage(1, []sanpltxt.Transfer{
+		&sanpltxt.Standard{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jerzy Kowalski",
+			Amount:        12312,
+			Mode:          sanpltxt.ModeElixir,
+			Title:         "zasilenie konta",
+			Date:          date(2020, 9, 1),
+		},
+		&sanpltxt.ZUS{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "20600000020260111122223333",
+			RecipientName: "ZUS",
+			Amount:        31994,
+			Title:         "Skladka ZUS",
+		},
+		&sanpltxt.SplitPayment{
+			DebitAccount:  "88102055581111103350100011",
+			CreditAccount: "50102055581111103350100016",
+			RecipientName: "Jan Nowak",
+			GrossAmount:   12350,
+			Mode:          sanpltxt.ModeInternal,
+			VATAmount:     2309,
+			RecipientNIP:  "8960005673",
+			InvoiceNumber: "5/2018",
+			Date:          date(2020, 9, 1),
+		},
+	}, nil)
+}
+
+func TestPackage_Summary(t *testing.T) {
+	s := summaryPackage().Summary()
+
+	assert.Equal(t, s.Count, 3)
+	assert.Equal(t, s.Amount, sanpltxt.Amount(56656))
+	assert.DeepEqual(t, s.ByType, map[int]sanpltxt.Totals{
+		1: {Count: 1, Amount: 12312},
+		2: {Count: 1, Amount: 31994},
+		6: {Count: 1, Amount: 12350},
+	})
+	assert.DeepEqual(t, s.ByDebitAccount, map[string]sanpltxt.Totals{
+		"51109010430000000100111111": {Count: 2, Amount: 44306},
+		"88102055581111103350100011": {Count: 1, Amount: 12350},
+	})
+	assert.DeepEqual(t, s.ByMode, map[sanpltxt.TransferMode]sanpltxt.Totals{
+		sanpltxt.ModeInternal: {Count: 1, Amount: 12350},
+		sanpltxt.ModeElixir:   {Count: 2, Amount: 44306},
+	})
+	assert.DeepEqual(t, s.ByDate, map[string]sanpltxt.Totals{
+		"":           {Count: 1, Amount: 31994},
+		"2020-09-01": {Count: 2, Amount: 24662},
+	})
+}
+
+func TestSummary_WriteCSV(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, summaryPackage().Summary().WriteCSV(&b))
+	assert.Equal(t, b.String(), `group,key,count,amount
+total,,3,566.56
+type,1,1,123.12
+type,2,1,319.94
+type,6,1,123.50
+debit_account,51109010430000000100111111,2,443.06
+debit_account,88102055581111103350100011,1,123.50
+mode,0,1,123.50
+mode,1,2,443.06
+date,,1,319.94
+date,2020-09-01,2,246.62
+`)
+}
+
+func TestSummary_WriteText(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, summaryPackage().Summary().WriteText(&b))
+
+	assert.Equal(t, b.String(), `Total                         3  566,56
+
+By transfer type
+  1 standard                  1  123,12
+  2 ZUS                       1  319,94
+  6 split payment             1  123,50
+
+By debit account
+  51109010430000000100111111  2  443,06
+  88102055581111103350100011  1  123,50
+
+By transfer mode
+  0 internal                  1  123,50
+  1 Elixir                    2  443,06
+
+By execution date
+  immediate                   1  319,94
+  2020-09-01                  2  246,62
+`)
+}
@@ -7,21 +7,21 @@ import (
 
 // Tax is a Type 3 (TaxOffice=true) or Type 4 (TaxOffice=false) transfer.
 type Tax struct {
-	TaxOffice      bool // true = type 3, false = type 4
-	DebitAccount   string
-	CreditAccount  string
-	RecipientName  string
-	Address        string
-	Amount         Amount
-	Date           *time.Time
-	PayerName      string
-	IdentifierType IdentifierType
-	Identifier     string
-	Year           string
-	PeriodType     PeriodType
-	PeriodNumber   string
-	FormSymbol     string
-	ObligationID   string
+	TaxOffice      bool           `json:"tax_office"` // true = type 3, false = type 4
+	DebitAccount   string         `json:"debit_account"`
+	CreditAccount  string         `json:"credit_account"`
+	RecipientName  string         `json:"recipient_name"`
+	Address        string         `json:"address,omitempty"`
+	Amount         Amount         `json:"amount"`
+	Date           *time.Time     `json:"date,omitempty"`
+	PayerName      string         `json:"payer_name"`
+	IdentifierType IdentifierType `json:"identifier_type"`
+	Identifier     string         `json:"identifier"`
+	Year           string         `json:"year,omitempty"`
+	PeriodType     PeriodType     `json:"period_type,omitempty"`
+	PeriodNumber   string         `json:"period_number,omitempty"`
+	FormSymbol     string         `json:"form_symbol"`
+	ObligationID   string         `json:"obligation_id,omitempty"`
 }
 
 var _ Transfer = (*Tax)(nil)
@@ -29,7 +29,7 @@ var _ Transfer = (*Tax)(nil)
 // Marshal returns the transfer in Santander format.
 func (t *Tax) Marshal() (string, error) {
 	var b strings.Builder
-	if err := t.marshal(&b); err != nil {
+	if err := marshalRecord(&b, t); err != nil {
 		return "", err
 	}
 	return b.String(), nil
@@ -37,7 +37,7 @@ func (t *Tax) Marshal() (string, error) {
 
 func (t *Tax) marshal(b *strings.Builder) error {
 	if err := t.validate(); err != nil {
-		return err
+		return annotate(err, -1, t)
 	}
 
 	if t.TaxOffice {
@@ -63,7 +63,7 @@ func (t *Tax) marshal(b *strings.Builder) error {
 	b.WriteString("|")
 	b.WriteString(string(t.IdentifierType))
 	b.WriteString("|")
-	b.WriteString(t.Identifier)
+	b.WriteString(normalizeIdentifier(t.Identifier, t.IdentifierType))
 	b.WriteString("|")
 	b.WriteString(t.Year)
 	b.WriteString("|")
@@ -80,41 +80,48 @@ func (t *Tax) marshal(b *strings.Builder) error {
 }
 
 func (t *Tax) validate() error {
-	if err := validateNRB(t.DebitAccount, "debit account"); err != nil {
-		return err
-	}
-	if err := validateNRB(t.CreditAccount, "credit account"); err != nil {
-		return err
-	}
-	if err := validateRecipientName(t.RecipientName); err != nil {
-		return err
-	}
-	if err := validateAddress(t.Address, false); err != nil {
-		return err
-	}
-	if err := validatePayerName(t.PayerName); err != nil {
-		return err
-	}
-	if err := validateIdentifierType(t.IdentifierType); err != nil {
-		return err
-	}
-	if err := validateIdentifier(t.Identifier, t.IdentifierType); err != nil {
-		return err
-	}
-	if err := validateYear(t.Year); err != nil {
-		return err
-	}
-	if err := validatePeriodType(t.PeriodType); err != nil {
-		return err
-	}
-	if err := validatePeriodNumber(t.PeriodNumber, t.PeriodType); err != nil {
+	return joinValidationErrors(
+		validateNRB(t.DebitAccount, "DebitAccount"),
+		validateNRB(t.CreditAccount, "CreditAccount"),
+		validateRecipientName(t.RecipientName),
+		validateAddress(t.Address, false),
+		validateAmount(t.Amount, "Amount"),
+		validatePayerName(t.PayerName),
+		validateIdentifierType(t.IdentifierType),
+		validateIdentifier(t.Identifier, t.IdentifierType),
+		validateYear(t.Year),
+		validatePeriodType(t.PeriodType),
+		validatePeriodNumber(t.PeriodNumber, t.PeriodType),
+		validateFormSymbol(t.FormSymbol),
+		validateObligationID(t.ObligationID),
+	)
+}
+
+func (t *Tax) unmarshal(f []string) error {
+	if err := checkFieldCount(f, 15); err != nil {
 		return err
 	}
-	if err := validateFormSymbol(t.FormSymbol); err != nil {
+
+	var err error
+	t.TaxOffice = f[0] == "3"
+	t.DebitAccount = f[1]
+	t.CreditAccount = f[2]
+	t.RecipientName = f[3]
+	t.Address = f[4]
+	if t.Amount, err = parseAmount(f, 5); err != nil {
 		return err
 	}
-	if err := validateObligationID(t.ObligationID); err != nil {
+	if t.Date, err = parseDate(f, 6); err != nil {
 		return err
 	}
+	t.PayerName = f[7]
+	t.IdentifierType = IdentifierType(f[8])
+	t.Identifier = f[9]
+	t.Year = f[10]
+	t.PeriodType = PeriodType(f[11])
+	t.PeriodNumber = f[12]
+	t.FormSymbol = f[13]
+	t.ObligationID = f[14]
+
 	return nil
 }
@@ -1,11 +1,12 @@
-// Package sanpltxt marshals Santander Bank's transfer import format.
+// Package sanpltxt marshals and parses Santander Bank's transfer import format.
 package sanpltxt
 
 import (
-	"errors"
-	"fmt"
+	"bytes"
+	"io"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // FormatVersion is the Santander format version.
@@ -22,6 +23,13 @@ type Transfer interface {
 // PackageOptions configures encoding behavior.
 type PackageOptions struct {
 	EncodeUTF8 bool // false (default) = Windows-1250, true = UTF-8
+
+	// Sanitize rewrites free-text fields of every transfer to fit their allowed
+	// characters and length limits before validation. Transfers passed in are
+	// not modified. See SanitizeTransfer.
+	Sanitize bool
+	// OnSanitize, if set, is called with every field changed by Sanitize.
+	OnSanitize func(SanitizeChange)
 }
 
 // Package is a collection of transfers for export.
@@ -43,10 +51,19 @@ func NewPackage(typ int, transfers []Transfer, opts *PackageOptions) *Package {
 	return p
 }
 
+// Type returns the package type: 1 = regular, 2 = payroll.
+func (p *Package) Type() int { return p.typ }
+
+// Transfers returns the transfers in the package.
+func (p *Package) Transfers() []Transfer { return p.transfers }
+
 // Marshal returns the package content as a UTF-8 string.
 func (p *Package) Marshal() (string, error) {
+	opts := p.options
+	opts.EncodeUTF8 = true
+
 	var b strings.Builder
-	if err := p.marshal(&b); err != nil {
+	if err := p.encode(&b, &opts); err != nil {
 		return "", err
 	}
 	return b.String(), nil
@@ -54,50 +71,207 @@ func (p *Package) Marshal() (string, error) {
 
 // MarshalBytes returns the package content with encoding based on options.
 func (p *Package) MarshalBytes() ([]byte, error) {
-	s, err := p.Marshal()
-	if err != nil {
+	var b bytes.Buffer
+	if err := p.Encode(&b); err != nil {
 		return nil, err
 	}
-	if p.options.EncodeUTF8 {
-		return []byte(s), nil
-	}
-	return ToWindows1250(s)
+	return b.Bytes(), nil
 }
 
-func (p *Package) marshal(b *strings.Builder) error {
-	if p.typ != 1 && p.typ != 2 {
-		return errors.New("package type must be 1 (regular) or 2 (payroll)")
-	}
+// Encode writes the package content to w with encoding based on options.
+// Transfers are streamed, so w may hold partial output if an error occurs.
+func (p *Package) Encode(w io.Writer) error {
+	return p.encode(w, &p.options)
+}
 
-	b.WriteString(FormatVersion)
-	b.WriteString("|")
-	b.WriteString(strconv.Itoa(p.typ))
-	b.WriteString("\n")
+func (p *Package) encode(w io.Writer, opts *PackageOptions) error {
+	enc := NewEncoder(w, p.typ, opts)
+	for _, t := range p.transfers {
+		if err := enc.Encode(t); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
 
+// Validate checks the package and all of its transfers without producing
+// output. It returns nil or ValidationErrors listing every problem found.
+func (p *Package) Validate() error {
+	var errs ValidationErrors
+	errs = appendValidationErrors(errs, checkPackageType(p.typ))
 	for i, t := range p.transfers {
-		// Validate transfer type matches package type
-		_, isPayroll := t.(*Payroll)
-		if p.typ == 1 && isPayroll {
-			return errors.New("payroll transfers (type 5) cannot be in regular packages (type 1)")
+		if p.options.Sanitize {
+			t, _ = SanitizeTransfer(t)
 		}
-		if p.typ == 2 && !isPayroll {
-			return errors.New("only payroll transfers (type 5) are allowed in payroll packages (type 2)")
+		err := joinValidationErrors(checkPackageTransfer(p.typ, t), validateTransfer(t))
+		if err == nil {
+			// Run the same final check as Encode, so that Validate and
+			// Marshal always agree.
+			var b strings.Builder
+			err = marshalRecord(&b, t)
 		}
+		errs = appendValidationErrors(errs, annotate(err, i, t))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateTransfer(t Transfer) error {
+	if v, ok := t.(interface{ validate() error }); ok {
+		return v.validate()
+	}
+	_, err := t.Marshal()
+	return err
+}
+
+func checkPackageType(typ int) error {
+	if typ != 1 && typ != 2 {
+		return newValidationError("", RulePackageType, strconv.Itoa(typ), "package type must be 1 (regular) or 2 (payroll)")
+	}
+	return nil
+}
+
+func checkPackageTransfer(typ int, t Transfer) error {
+	// Validate transfer type matches package type
+	_, isPayroll := t.(*Payroll)
+	if typ == 1 && isPayroll {
+		return newValidationError("", RulePackageType, "", "payroll transfers (type 5) cannot be in regular packages (type 1)")
+	}
+	if typ == 2 && !isPayroll {
+		return newValidationError("", RulePackageType, "", "only payroll transfers (type 5) are allowed in payroll packages (type 2)")
+	}
+	return nil
+}
+
+// recordFields is the number of "|"-terminated fields on a line, including
+// the transfer type, for each transfer type.
+var recordFields = [...]int{1: 10, 2: 9, 3: 15, 4: 15, 5: 9, 6: 9}
+
+// checkRecord guarantees that a marshaled transfer is exactly one line with
+// the field count of its type, whatever the per-field character sets allow.
+// A stray delimiter or line break would otherwise shift columns or inject
+// another transfer.
+func checkRecord(line string, typ int) error {
+	if strings.ContainsAny(line, "\r\n") {
+		return newValidationError("", RuleDelimiter, line, "transfer contains a line break")
+	}
+	if typ > 0 && strings.Count(line, "|") != recordFields[typ] {
+		return newValidationError("", RuleDelimiter, line, "transfer contains a field delimiter (|) inside a field")
+	}
+	return nil
+}
 
-		if m, ok := t.(interface{ marshal(*strings.Builder) error }); ok {
-			if err := m.marshal(b); err != nil {
-				return fmt.Errorf("transfer %d: %w", i, err)
-			}
-		} else {
-			line, err := t.Marshal()
-			if err != nil {
-				return fmt.Errorf("transfer %d: %w", i, err)
-			}
-			b.WriteString(line)
+// transferType returns the type number of t as written in the file, or 0 if
+// t is not one of the package's transfer types.
+func transferType(t Transfer) int {
+	switch t := t.(type) {
+	case *Standard:
+		return 1
+	case *ZUS:
+		return 2
+	case *Tax:
+		if t.TaxOffice {
+			return 3
 		}
-		b.WriteString("\n")
+		return 4
+	case *Payroll:
+		return 5
+	case *SplitPayment:
+		return 6
 	}
+	return 0
+}
+
+// transferInfo holds the fields that all transfer types have in common.
+type transferInfo struct {
+	typ    int
+	debit  string
+	credit string
+	amount Amount
+	mode   TransferMode
+	title  string // for Tax, the identifying tax fields
+	date   *time.Time
+}
+
+func infoOf(t Transfer) transferInfo {
+	switch t := t.(type) {
+	case *Standard:
+		return transferInfo{1, t.DebitAccount, t.CreditAccount, t.Amount, t.Mode, t.Title, t.Date}
+	case *ZUS:
+		return transferInfo{2, t.DebitAccount, t.CreditAccount, t.Amount, ModeElixir, t.Title, t.Date}
+	case *Tax:
+		title := strings.Join([]string{string(t.IdentifierType), t.Identifier, t.Year, string(t.PeriodType), t.PeriodNumber, t.FormSymbol, t.ObligationID}, "|")
+		return transferInfo{transferType(t), t.DebitAccount, t.CreditAccount, t.Amount, ModeElixir, title, t.Date}
+	case *Payroll:
+		return transferInfo{5, t.DebitAccount, t.CreditAccount, t.Amount, t.Mode, t.Title, t.Date}
+	case *SplitPayment:
+		var b strings.Builder
+		t.formatTitle(&b)
+		return transferInfo{6, t.DebitAccount, t.CreditAccount, t.GrossAmount, t.Mode, b.String(), t.Date}
+	}
+	return transferInfo{}
+}
+
+// dateKey returns d as YYYY-MM-DD, or "" for transfers executed immediately.
+func dateKey(d *time.Time) string {
+	if d == nil {
+		return ""
+	}
+	return d.Format(time.DateOnly)
+}
+
+// annotate records the position and type of t on the validation errors in err.
+func annotate(err error, index int, t Transfer) error {
+	typ := transferType(t)
+	walkValidationErrors(err, func(e *ValidationError) {
+		e.TransferIndex = index
+		e.TransferType = typ
+	})
+	return err
+}
+
+func isValidationError(err error) bool {
+	found := false
+	walkValidationErrors(err, func(*ValidationError) { found = true })
+	return found
+}
 
+func walkValidationErrors(err error, fn func(*ValidationError)) {
+	switch e := err.(type) {
+	case *ValidationError:
+		fn(e)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			walkValidationErrors(err, fn)
+		}
+	case interface{ Unwrap() error }:
+		walkValidationErrors(e.Unwrap(), fn)
+	}
+}
+
+// marshalRecord appends the line of t to b and checks it with checkRecord.
+func marshalRecord(b *strings.Builder, t Transfer) error {
+	start := b.Len()
+	if err := marshalTransfer(b, t); err != nil {
+		return err
+	}
+	if err := checkRecord(b.String()[start:], transferType(t)); err != nil {
+		return annotate(err, -1, t)
+	}
+	return nil
+}
+
+func marshalTransfer(b *strings.Builder, t Transfer) error {
+	if m, ok := t.(interface{ marshal(*strings.Builder) error }); ok {
+		return m.marshal(b)
+	}
+	line, err := t.Marshal()
+	if err != nil {
+		return err
+	}
+	b.WriteString(line)
 	return nil
 }
 
@@ -114,24 +288,13 @@ const (
 
 func (m TransferMode) String() string { return strconv.Itoa(int(m)) }
 
-// Amount is a monetary value in grosze (1/100 PLN).
-type Amount int64
-
-func (a Amount) String() string {
-	if a < 0 {
-		a = -a
-	}
-	zloty := a / 100
-	grosze := a % 100
-	if grosze == 0 {
-		return strconv.FormatInt(int64(zloty), 10)
-	}
-	var b strings.Builder
-	b.WriteString(strconv.FormatInt(int64(zloty), 10))
-	b.WriteString(",")
-	if grosze < 10 {
-		b.WriteString("0")
-	}
-	b.WriteString(strconv.FormatInt(int64(grosze), 10))
-	return b.String()
+var modeNames = map[TransferMode]string{
+	ModeInternal:      "internal",
+	ModeElixir:        "Elixir",
+	ModeSORBNET:       "SORBNET",
+	ModeExpressElixir: "Express Elixir",
 }
+
+// Name returns the human-readable name of the mode, such as "Express
+// Elixir", or "" if the mode is unknown.
+func (m TransferMode) Name() string { return modeNames[m] }
@@ -29,6 +29,7 @@ func TestAmount_String(t *testing.T) {
 		{100, "1"},
 		{1, "0,01"},
 		{10, "0,10"},
+		{-50000, "-500"},
 	}
 
 	for _, tt := range tests {
@@ -39,7 +40,13 @@ func TestAmount_String(t *testing.T) {
 	}
 }
 
+func TestTransferMode_Name(t *testing.T) {
+	assert.Equal(t, sanpltxt.ModeExpressElixir.Name(), "Express Elixir")
+	assert.Equal(t, sanpltxt.TransferMode(7).Name(), "")
+}
+
 // PDF example: 1|51109010430000000100111111|50102055581111103350100016|Jerzy Kowalski|Warszawa ul. Kaliska 123 00-123|123,12|1|zasielenie konta|01-09-2020|7850000000|
+// The fixture uses a NIP with a valid check digit instead.
 func TestStandard_Marshal(t *testing.T) {
 	s := &sanpltxt.Standard{
 		DebitAccount:  "51109010430000000100111111",
@@ -50,21 +57,22 @@ func TestStandard_Marshal(t *testing.T) {
 		Mode:          sanpltxt.ModeElixir,
 		Title:         "zasielenie konta",
 		Date:          date(2020, 9, 1),
-		NIP:           "7850000000",
+		NIP:           "7850000007",
 	}
 
 	got, err := s.Marshal()
 	assert.NoError(t, err)
 
-	want := "1|51109010430000000100111111|50102055581111103350100016|Jerzy Kowalski|Warszawa ul. Kaliska 123 00-123|123,12|1|zasielenie konta|01-09-2020|7850000000|"
+	want := "1|51109010430000000100111111|50102055581111103350100016|Jerzy Kowalski|Warszawa ul. Kaliska 123 00-123|123,12|1|zasielenie konta|01-09-2020|7850000007|"
 	assert.Equal(t, got, want)
 }
 
 // PDF example: 2|51109010430000000100111111|82600000020260111122223333|ZUS|Warszawa ul. Szamocka 3,5 01748|319,94|1|Skladka ZUS|01-09-2020|
+// The fixture uses a credit account with valid check digits instead.
 func TestZUS_Marshal(t *testing.T) {
 	z := &sanpltxt.ZUS{
 		DebitAccount:  "51109010430000000100111111",
-		CreditAccount: "82600000020260111122223333",
+		CreditAccount: "20600000020260111122223333",
 		RecipientName: "ZUS",
 		Address:       "Warszawa ul. Szamocka 3,5 01748",
 		Amount:        31994, // 319,94 PLN
@@ -75,11 +83,12 @@ func TestZUS_Marshal(t *testing.T) {
 	got, err := z.Marshal()
 	assert.NoError(t, err)
 
-	want := "2|51109010430000000100111111|82600000020260111122223333|ZUS|Warszawa ul. Szamocka 3,5 01748|319,94|1|Skladka ZUS|01-09-2020|"
+	want := "2|51109010430000000100111111|20600000020260111122223333|ZUS|Warszawa ul. Szamocka 3,5 01748|319,94|1|Skladka ZUS|01-09-2020|"
 	assert.Equal(t, got, want)
 }
 
 // PDF example: 3|51109010430000000100111111|06101014690039392223000000|Urzad Skarbowy Poznan Winogrady|Poznan Wojciechowskiego 3/5 60-685|1000|01-09-2020|Jan Kowalski|N|9721230101|05|M|07|PIT5|id.zobowiazania|
+// The fixture uses a NIP with a valid check digit instead.
 func TestTax_Marshal(t *testing.T) {
 	tax := &sanpltxt.Tax{
 		TaxOffice:      true,
@@ -91,7 +100,7 @@ func TestTax_Marshal(t *testing.T) {
 		Date:           date(2020, 9, 1),
 		PayerName:      "Jan Kowalski",
 		IdentifierType: sanpltxt.IdentifierNIP,
-		Identifier:     "9721230101",
+		Identifier:     "9721230108",
 		Year:           "05",
 		PeriodType:     sanpltxt.PeriodMonth,
 		PeriodNumber:   "07",
@@ -102,7 +111,7 @@ func TestTax_Marshal(t *testing.T) {
 	got, err := tax.Marshal()
 	assert.NoError(t, err)
 
-	want := "3|51109010430000000100111111|06101014690039392223000000|Urzad Skarbowy Poznan Winogrady|Poznan Wojciechowskiego 3/5 60-685|1000|01-09-2020|Jan Kowalski|N|9721230101|05|M|07|PIT5|id.zobowiazania|"
+	want := "3|51109010430000000100111111|06101014690039392223000000|Urzad Skarbowy Poznan Winogrady|Poznan Wojciechowskiego 3/5 60-685|1000|01-09-2020|Jan Kowalski|N|9721230108|05|M|07|PIT5|id.zobowiazania|"
 	assert.Equal(t, got, want)
 }
 
@@ -127,16 +136,17 @@ func TestPayroll_Marshal(t *testing.T) {
 }
 
 // PDF example: 6|51109010430000000100111111|50102055581111103350100011|Jan Nowak|Warszawa ul. Mickiewicza 11 02-222|123,5|1|/VAT/23,09/IDC/8960005670/INV/5/2018/TXT/Faktura 5/2018|30-09-2020|
+// The fixture uses a credit account and NIP with valid check digits instead.
 func TestSplitPayment_Marshal(t *testing.T) {
 	sp := &sanpltxt.SplitPayment{
 		DebitAccount:  "51109010430000000100111111",
-		CreditAccount: "50102055581111103350100011",
+		CreditAccount: "88102055581111103350100011",
 		RecipientName: "Jan Nowak",
 		Address:       "Warszawa ul. Mickiewicza 11 02-222",
 		GrossAmount:   12350, // 123,50 PLN
 		Mode:          sanpltxt.ModeElixir,
 		VATAmount:     2309, // 23,09 PLN
-		RecipientNIP:  "8960005670",
+		RecipientNIP:  "8960005673",
 		InvoiceNumber: "5/2018",
 		FreeText:      "Faktura 5/2018",
 		Date:          date(2020, 9, 30),
@@ -145,7 +155,7 @@ func TestSplitPayment_Marshal(t *testing.T) {
 	got, err := sp.Marshal()
 	assert.NoError(t, err)
 
-	want := "6|51109010430000000100111111|50102055581111103350100011|Jan Nowak|Warszawa ul. Mickiewicza 11 02-222|123,50|1|/VAT/23,09/IDC/8960005670/INV/5/2018/TXT/Faktura 5/2018|30-09-2020|"
+	want := "6|51109010430000000100111111|88102055581111103350100011|Jan Nowak|Warszawa ul. Mickiewicza 11 02-222|123,50|1|/VAT/23,09/IDC/8960005673/INV/5/2018/TXT/Faktura 5/2018|30-09-2020|"
 	assert.Equal(t, got, want)
 }
 
@@ -160,11 +170,11 @@ func TestPackage_Marshal_Regular(t *testing.T) {
 			Mode:          sanpltxt.ModeElixir,
 			Title:         "zasielenie konta",
 			Date:          date(2020, 9, 1),
-			NIP:           "7850000000",
+			NIP:           "7850000007",
 		},
 		&sanpltxt.ZUS{
 			DebitAccount:  "51109010430000000100111111",
-			CreditAccount: "82600000020260111122223333",
+			CreditAccount: "20600000020260111122223333",
 			RecipientName: "ZUS",
 			Address:       "Warszawa ul. Szamocka 3,5 01748",
 			Amount:        31994,
@@ -1,11 +1,160 @@
 package sanpltxt
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
+)
+
+// Rule identifies the validation rule that a field violated.
+type Rule string
+
+// Validation rules.
+const (
+	RuleRequired     Rule = "required"
+	RuleTooLong      Rule = "too_long"
+	RuleFormat       Rule = "invalid_format"
+	RuleInvalidChars Rule = "invalid_chars"
+	RuleChecksum     Rule = "checksum"
+	RuleInvalidValue Rule = "invalid_value"
+	RuleInternalMode Rule = "internal_mode"
+	RulePackageType  Rule = "package_type"
+	RuleDelimiter    Rule = "delimiter"
+	RuleNotPositive  Rule = "not_positive"
+	RuleAmountLimit  Rule = "amount_limit"
+)
+
+// ValidationError describes a transfer field that failed validation. Use
+// errors.As to retrieve it from errors returned by Marshal and Encode.
+type ValidationError struct {
+	TransferIndex int    // position in the package, -1 for a standalone transfer
+	TransferType  int    // transfer type 1-6, 0 if unknown
+	Field         string // struct field name, e.g. "CreditAccount"; empty for package-level errors
+	Rule          Rule
+	Value         string
+	Min, Max      int // length limits in characters, 0 if not applicable
+	Length        int // length of Value in characters, for length rules
+
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	if e.TransferIndex >= 0 {
+		return "transfer " + strconv.Itoa(e.TransferIndex) + ": " + e.msg
+	}
+	return e.msg
+}
+
+// ValidationErrors lists every validation error found, as returned by
+// Package.Validate and by Marshal for a transfer with several invalid fields.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	var b strings.Builder
+	for i, err := range e {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors in the list, so errors.As finds the first one.
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// joinValidationErrors returns the non-nil errs as ValidationErrors, or nil if
+// there are none.
+func joinValidationErrors(errs ...error) error {
+	var list ValidationErrors
+	for _, err := range errs {
+		list = appendValidationErrors(list, err)
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+func appendValidationErrors(list ValidationErrors, err error) ValidationErrors {
+	if err == nil {
+		return list
+	}
+	n := len(list)
+	walkValidationErrors(err, func(e *ValidationError) {
+		list = append(list, e)
+	})
+	if len(list) == n {
+		// Custom Transfer implementations may return arbitrary errors.
+		list = append(list, newValidationError("", RuleInvalidValue, "", err.Error()))
+	}
+	return list
+}
+
+func newValidationError(field string, rule Rule, value, msg string) *ValidationError {
+	return &ValidationError{
+		TransferIndex: -1,
+		Field:         field,
+		Rule:          rule,
+		Value:         value,
+		msg:           msg,
+	}
+}
+
+func errRequired(field, label string) error {
+	return newValidationError(field, RuleRequired, "", label+" is required")
+}
+
+func errTooLong(field, label, value string, limit int) error {
+	e := newValidationError(field, RuleTooLong, value, fmt.Sprintf("%s must be at most %d characters, got %d", label, limit, charCount(value)))
+	e.Max = limit
+	e.Length = charCount(value)
+	return e
+}
+
+func errInvalidChars(field, label, value string) error {
+	return newValidationError(field, RuleInvalidChars, value, label+" contains invalid characters")
+}
+
+func errLength(field, value string, length int, msg string) error {
+	e := newValidationError(field, RuleFormat, value, msg)
+	e.Min = length
+	e.Max = length
+	e.Length = charCount(value)
+	return e
+}
+
+var fieldLabels = map[string]string{
+	"DebitAccount":  "debit account",
+	"CreditAccount": "credit account",
+}
+
+func fieldLabel(field string) string {
+	if l, ok := fieldLabels[field]; ok {
+		return l
+	}
+	return field
+}
+
+// Length limits of free-text fields, in characters.
+const (
+	maxRecipientName = 80
+	maxAddress       = 60
+	maxTitle         = 140
+	maxPayerName     = 50
+	maxFormSymbol    = 6
+	maxObligationID  = 20
+	maxInvoiceNumber = 35
+	maxFreeText      = 33
 )
 
 const polishChars = "ąćęłńóśźżĄĆĘŁŃÓŚŹŻ"
@@ -19,6 +168,7 @@ var (
 	charsObligationID  = buildCharSet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-.,:; " + polishChars)
 	charsInvoice       = buildCharSet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-.,:;/ " + polishChars)
 	charsFreeText      = charsInvoice
+	charsIdentifier    = buildCharSet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-./ ")
 )
 
 func buildCharSet(s string) map[rune]struct{} {
@@ -40,42 +190,165 @@ func containsOnly(s string, allowed map[rune]struct{}) bool {
 
 func isDigitsOnly(s string) bool {
 	for _, r := range s {
-		if !unicode.IsDigit(r) {
+		if r < '0' || r > '9' {
 			return false
 		}
 	}
 	return true
 }
 
-func validateNRB(account, fieldName string) error {
-	if len(account) != 26 {
-		return fmt.Errorf("%s must be exactly 26 digits, got %d", fieldName, len(account))
+// charCount returns the length of s as the bank counts it. Every allowed
+// character is a single byte in Windows-1250, so this is the number of runes
+// rather than UTF-8 bytes.
+func charCount(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+func validateNRB(account, field string) error {
+	label := fieldLabel(field)
+	if charCount(account) != 26 {
+		return errLength(field, account, 26, fmt.Sprintf("%s must be exactly 26 digits, got %d", label, charCount(account)))
 	}
 	if !isDigitsOnly(account) {
-		return fmt.Errorf("%s must contain only digits", fieldName)
+		return newValidationError(field, RuleInvalidChars, account, label+" must contain only digits")
+	}
+	if want := nrbCheckDigits(account); account[:2] != want {
+		return newValidationError(field, RuleChecksum, account, fmt.Sprintf("%s has invalid check digits %s, expected %s", label, account[:2], want))
 	}
 	return nil
 }
 
-func validateNIP(nip string) error {
-	if len(nip) != 10 {
-		return fmt.Errorf("NIP must be exactly 10 digits, got %d", len(nip))
+// ValidateNRB checks that nrb is a valid Polish account number (NRB),
+// including its ISO 7064 mod 97-10 check digits. Spaces and a leading "PL"
+// country code are accepted; use NormalizeNRB to obtain the 26-digit form
+// required by transfers.
+func ValidateNRB(nrb string) error {
+	return validateNRB(NormalizeNRB(nrb), "NRB")
+}
+
+// NormalizeNRB removes spaces and a leading "PL" country code from nrb.
+func NormalizeNRB(nrb string) string {
+	nrb = strings.Join(strings.Fields(nrb), "")
+	if len(nrb) >= 2 && strings.EqualFold(nrb[:2], "PL") {
+		nrb = nrb[2:]
+	}
+	return nrb
+}
+
+// nrbCheckDigits computes the check digits of a 26-digit NRB as in the
+// PL-prefixed IBAN: the BBAN followed by "PL00" must be congruent to 98 - check
+// digits modulo 97.
+func nrbCheckDigits(nrb string) string {
+	r := 0
+	for _, c := range nrb[2:] + "252100" { // "PL00" with P=25, L=21
+		r = (r*10 + int(c-'0')) % 97
+	}
+	check := 98 - r
+	return string([]byte{byte('0' + check/10), byte('0' + check%10)})
+}
+
+// validateNIP checks nip after normalizing it with NormalizeNIP, the form in
+// which it is marshaled.
+func validateNIP(nip, field string) error {
+	nip = NormalizeNIP(nip)
+	if charCount(nip) != 10 {
+		return errLength(field, nip, 10, fmt.Sprintf("NIP must be exactly 10 digits, got %d", charCount(nip)))
 	}
 	if !isDigitsOnly(nip) {
-		return errors.New("NIP must contain only digits")
+		return newValidationError(field, RuleInvalidChars, nip, "NIP must contain only digits")
+	}
+	if weightedSum(nip, nipWeights)%11 != lastDigit(nip) {
+		return newValidationError(field, RuleChecksum, nip, "NIP has invalid check digit")
+	}
+	return nil
+}
+
+// ValidateNIP checks that nip is a valid Polish tax identification number,
+// including its check digit. Input is normalized with NormalizeNIP first.
+func ValidateNIP(nip string) error {
+	return validateNIP(nip, "NIP")
+}
+
+// NormalizeNIP removes a leading "PL" country code, spaces and hyphens from
+// nip, so that "PL 123-456-78-90" becomes "1234567890".
+func NormalizeNIP(nip string) string {
+	nip = strings.TrimSpace(nip)
+	if len(nip) >= 2 && strings.EqualFold(nip[:2], "PL") {
+		nip = nip[2:]
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, nip)
+}
+
+var (
+	nipWeights     = []int{6, 5, 7, 2, 3, 4, 5, 6, 7}
+	peselWeights   = []int{1, 3, 7, 9, 1, 3, 7, 9, 1, 3}
+	regon9Weights  = []int{8, 9, 2, 3, 4, 5, 6, 7}
+	regon14Weights = []int{2, 4, 8, 5, 0, 9, 7, 3, 6, 1, 2, 4, 8}
+)
+
+// weightedSum returns the sum of the leading digits of s multiplied by weights.
+func weightedSum(s string, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += int(s[i]-'0') * w
+	}
+	return sum
+}
+
+func lastDigit(s string) int { return int(s[len(s)-1] - '0') }
+
+func validatePESEL(pesel string) error {
+	if len(pesel) != 11 || !isDigitsOnly(pesel) {
+		return errLength("Identifier", pesel, 11, "PESEL must be exactly 11 digits")
+	}
+	if (10-weightedSum(pesel, peselWeights)%10)%10 != lastDigit(pesel) {
+		return newValidationError("Identifier", RuleChecksum, pesel, "PESEL has invalid check digit")
+	}
+
+	year, _ := strconv.Atoi(pesel[0:2])
+	month, _ := strconv.Atoi(pesel[2:4])
+	day, _ := strconv.Atoi(pesel[4:6])
+	// The century is encoded by adding 80 (1800s), 0 (1900s), 20 (2000s),
+	// 40 (2100s) or 60 (2200s) to the month.
+	century := [...]int{1900, 2000, 2100, 2200, 1800}[month/20]
+	month %= 20
+	if month < 1 || month > 12 {
+		return newValidationError("Identifier", RuleInvalidValue, pesel, "PESEL has invalid birth month")
+	}
+	birth := time.Date(century+year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if birth.Day() != day {
+		return newValidationError("Identifier", RuleInvalidValue, pesel, "PESEL has invalid birth date")
+	}
+	return nil
+}
+
+func validateREGON(regon string) error {
+	if (len(regon) != 9 && len(regon) != 14) || !isDigitsOnly(regon) {
+		return newValidationError("Identifier", RuleFormat, regon, "REGON must be 9 or 14 digits")
+	}
+	if weightedSum(regon, regon9Weights)%11%10 != int(regon[8]-'0') {
+		return newValidationError("Identifier", RuleChecksum, regon, "REGON has invalid check digit")
+	}
+	if len(regon) == 14 && weightedSum(regon, regon14Weights)%11%10 != lastDigit(regon) {
+		return newValidationError("Identifier", RuleChecksum, regon, "REGON has invalid check digit")
 	}
 	return nil
 }
 
 func validateRecipientName(name string) error {
 	if name == "" {
-		return errors.New("recipient name is required")
+		return errRequired("RecipientName", "recipient name")
 	}
-	if len(name) > 80 {
-		return fmt.Errorf("recipient name must be at most 80 characters, got %d", len(name))
+	if charCount(name) > maxRecipientName {
+		return errTooLong("RecipientName", "recipient name", name, maxRecipientName)
 	}
 	if !containsOnly(name, charsRecipientName) {
-		return errors.New("recipient name contains invalid characters")
+		return errInvalidChars("RecipientName", "recipient name", name)
 	}
 	return nil
 }
@@ -83,54 +356,54 @@ func validateRecipientName(name string) error {
 func validateAddress(address string, required bool) error {
 	if address == "" {
 		if required {
-			return errors.New("address is required")
+			return errRequired("Address", "address")
 		}
 		return nil
 	}
-	if len(address) > 60 {
-		return fmt.Errorf("address must be at most 60 characters, got %d", len(address))
+	if charCount(address) > maxAddress {
+		return errTooLong("Address", "address", address, maxAddress)
 	}
 	if !containsOnly(address, charsAddress) {
-		return errors.New("address contains invalid characters")
+		return errInvalidChars("Address", "address", address)
 	}
 	return nil
 }
 
 func validateTitle(title string) error {
 	if title == "" {
-		return errors.New("title is required")
+		return errRequired("Title", "title")
 	}
-	if len(title) > 140 {
-		return fmt.Errorf("title must be at most 140 characters, got %d", len(title))
+	if charCount(title) > maxTitle {
+		return errTooLong("Title", "title", title, maxTitle)
 	}
 	if !containsOnly(title, charsTitle) {
-		return errors.New("title contains invalid characters")
+		return errInvalidChars("Title", "title", title)
 	}
 	return nil
 }
 
 func validatePayerName(name string) error {
 	if name == "" {
-		return errors.New("payer name is required")
+		return errRequired("PayerName", "payer name")
 	}
-	if len(name) > 50 {
-		return fmt.Errorf("payer name must be at most 50 characters, got %d", len(name))
+	if charCount(name) > maxPayerName {
+		return errTooLong("PayerName", "payer name", name, maxPayerName)
 	}
 	if !containsOnly(name, charsPayerName) {
-		return errors.New("payer name contains invalid characters")
+		return errInvalidChars("PayerName", "payer name", name)
 	}
 	return nil
 }
 
 func validateFormSymbol(symbol string) error {
 	if symbol == "" {
-		return errors.New("form symbol is required")
+		return errRequired("FormSymbol", "form symbol")
 	}
-	if len(symbol) > 6 {
-		return fmt.Errorf("form symbol must be at most 6 characters, got %d", len(symbol))
+	if charCount(symbol) > maxFormSymbol {
+		return errTooLong("FormSymbol", "form symbol", symbol, maxFormSymbol)
 	}
 	if !containsOnly(symbol, charsFormSymbol) {
-		return errors.New("form symbol contains invalid characters (allowed: 0-9, A-Z, -)")
+		return newValidationError("FormSymbol", RuleInvalidChars, symbol, "form symbol contains invalid characters (allowed: 0-9, A-Z, -)")
 	}
 	return nil
 }
@@ -139,24 +412,29 @@ func validateObligationID(id string) error {
 	if id == "" {
 		return nil // optional field
 	}
-	if len(id) > 20 {
-		return fmt.Errorf("obligation ID must be at most 20 characters, got %d", len(id))
+	if charCount(id) > maxObligationID {
+		return errTooLong("ObligationID", "obligation ID", id, maxObligationID)
 	}
 	if !containsOnly(id, charsObligationID) {
-		return errors.New("obligation ID contains invalid characters")
+		return errInvalidChars("ObligationID", "obligation ID", id)
 	}
 	return nil
 }
 
 func validateInvoiceNumber(num string) error {
 	if num == "" {
-		return errors.New("invoice number is required")
+		return errRequired("InvoiceNumber", "invoice number")
 	}
-	if len(num) > 35 {
-		return fmt.Errorf("invoice number must be at most 35 characters, got %d", len(num))
+	if charCount(num) > maxInvoiceNumber {
+		return errTooLong("InvoiceNumber", "invoice number", num, maxInvoiceNumber)
 	}
 	if !containsOnly(num, charsInvoice) {
-		return errors.New("invoice number contains invalid characters")
+		return errInvalidChars("InvoiceNumber", "invoice number", num)
+	}
+	// The title ends the invoice number at the first /TXT/, including one
+	// formed with the /TXT/ written before the free text.
+	if strings.Contains(num+"/", "/TXT/") {
+		return newValidationError("InvoiceNumber", RuleDelimiter, num, "invoice number must not contain /TXT/, which starts the free text in the title")
 	}
 	return nil
 }
@@ -165,11 +443,11 @@ func validateFreeText(text string) error {
 	if text == "" {
 		return nil // optional field
 	}
-	if len(text) > 33 {
-		return fmt.Errorf("free text must be at most 33 characters, got %d", len(text))
+	if charCount(text) > maxFreeText {
+		return errTooLong("FreeText", "free text", text, maxFreeText)
 	}
 	if !containsOnly(text, charsFreeText) {
-		return errors.New("free text contains invalid characters")
+		return errInvalidChars("FreeText", "free text", text)
 	}
 	return nil
 }
@@ -184,7 +462,48 @@ func validateTransferMode(mode TransferMode, allowedModes ...TransferMode) error
 	for _, m := range allowedModes {
 		modes = append(modes, m.String())
 	}
-	return errors.New("transfer mode must be one of: " + strings.Join(modes, ", "))
+	return newValidationError("Mode", RuleInvalidValue, mode.String(), "transfer mode must be one of: "+strings.Join(modes, ", "))
+}
+
+func validateAmount(a Amount, field string) error {
+	if a <= 0 {
+		return newValidationError(field, RuleNotPositive, a.String(), "amount must be positive, got "+a.String())
+	}
+	if a > MaxAmount {
+		return newValidationError(field, RuleAmountLimit, a.String(), "amount must be at most "+MaxAmount.String()+", got "+a.String())
+	}
+	return nil
+}
+
+// validateModeAmount checks the amount against the limits of the transfer
+// mode's clearing system.
+func validateModeAmount(mode TransferMode, a Amount, field string) error {
+	switch {
+	case mode == ModeExpressElixir && a > MaxExpressElixirAmount:
+		return newValidationError(field, RuleAmountLimit, a.String(), "Express Elixir amount must be at most "+MaxExpressElixirAmount.String()+", got "+a.String())
+	case mode == ModeSORBNET && a < MinSORBNETAmount:
+		return newValidationError(field, RuleAmountLimit, a.String(), "SORBNET amount must be at least "+MinSORBNETAmount.String()+", got "+a.String())
+	}
+	return nil
+}
+
+func validateVATAmount(vat, gross Amount) error {
+	if vat < 0 {
+		return newValidationError("VATAmount", RuleInvalidValue, vat.String(), "VAT amount must not be negative, got "+vat.String())
+	}
+	if vat > gross {
+		return newValidationError("VATAmount", RuleInvalidValue, vat.String(), "VAT amount "+vat.String()+" must not exceed gross amount "+gross.String())
+	}
+	return nil
+}
+
+// validateInternalMode rejects ModeInternal for accounts held outside
+// Santander, which the bank would otherwise reject after upload.
+func validateInternalMode(mode TransferMode, creditAccount string) error {
+	if mode != ModeInternal || isSantanderAccount(creditAccount) || validateNRB(creditAccount, "") != nil {
+		return nil
+	}
+	return newValidationError("Mode", RuleInternalMode, mode.String(), "internal transfer mode requires a Santander credit account (sort code "+santanderSortCodePrefix+"x); use ModeElixir instead")
 }
 
 // IdentifierType is the type of tax identifier.
@@ -205,39 +524,80 @@ func validateIdentifierType(t IdentifierType) error {
 	case IdentifierNIP, IdentifierREGON, IdentifierPESEL, IdentifierID, IdentifierPassport, IdentifierOther:
 		return nil
 	}
-	return errors.New("identifier type must be one of: N (NIP), R (REGON), P (PESEL), 1 (ID), 2 (Passport), 3 (Other)")
+	return newValidationError("IdentifierType", RuleInvalidValue, string(t), "identifier type must be one of: N (NIP), R (REGON), P (PESEL), 1 (ID), 2 (Passport), 3 (Other)")
 }
 
 func validateIdentifier(id string, idType IdentifierType) error {
 	if id == "" {
-		return errors.New("identifier is required")
+		return errRequired("Identifier", "identifier")
 	}
 
 	switch idType {
 	case IdentifierNIP:
-		if len(id) != 10 || !isDigitsOnly(id) {
-			return errors.New("NIP must be exactly 10 digits")
-		}
+		return validateNIP(id, "Identifier")
 	case IdentifierREGON:
-		if (len(id) != 9 && len(id) != 14) || !isDigitsOnly(id) {
-			return errors.New("REGON must be 9 or 14 digits")
-		}
+		return validateREGON(id)
 	case IdentifierPESEL:
-		if len(id) != 11 || !isDigitsOnly(id) {
-			return errors.New("PESEL must be exactly 11 digits")
-		}
+		return validatePESEL(id)
 	case IdentifierID:
-		if len(id) < 8 || len(id) > 9 {
-			return errors.New("ID card number must be 8 or 9 characters")
+		return validateDocumentNumber(normalizeIdentifier(id, idType), 3, idCardWeights, "ID card")
+	case IdentifierPassport:
+		return validateDocumentNumber(normalizeIdentifier(id, idType), 2, passportWeights, "passport")
+	case IdentifierOther:
+		if charCount(id) > 14 {
+			return errTooLong("Identifier", "identifier", id, 14)
 		}
-	case IdentifierPassport, IdentifierOther:
-		if len(id) > 14 {
-			return errors.New("identifier must be at most 14 characters")
+		if !containsOnly(id, charsIdentifier) {
+			return errInvalidChars("Identifier", "identifier", id)
 		}
 	}
 	return nil
 }
 
+// normalizeIdentifier normalizes NIPs with NormalizeNIP, and upper-cases ID
+// card and passport numbers and removes whitespace from them. Other
+// identifiers are returned unchanged.
+func normalizeIdentifier(id string, idType IdentifierType) string {
+	switch idType {
+	case IdentifierNIP:
+		return NormalizeNIP(id)
+	case IdentifierID, IdentifierPassport:
+		return strings.ToUpper(strings.Join(strings.Fields(id), ""))
+	}
+	return id
+}
+
+// Polish ID card (AAA999999) and passport (AA9999999) numbers carry a check
+// digit right after the letter series. Its weight of 9 makes a valid number's
+// weighted sum divisible by 10.
+var (
+	idCardWeights   = []int{7, 3, 1, 9, 7, 3, 1, 7, 3}
+	passportWeights = []int{7, 3, 9, 1, 7, 3, 1, 7, 3}
+)
+
+func validateDocumentNumber(num string, letters int, weights []int, name string) error {
+	formatErr := newValidationError("Identifier", RuleFormat, num, fmt.Sprintf("%s number must be %d letters followed by %d digits", name, letters, len(weights)-letters))
+	if len(num) != len(weights) {
+		return formatErr
+	}
+	sum := 0
+	for i := range len(num) {
+		c := num[i]
+		switch {
+		case i < letters && c >= 'A' && c <= 'Z':
+			sum += (int(c-'A') + 10) * weights[i]
+		case i >= letters && c >= '0' && c <= '9':
+			sum += int(c-'0') * weights[i]
+		default:
+			return formatErr
+		}
+	}
+	if sum%10 != 0 {
+		return newValidationError("Identifier", RuleChecksum, num, name+" number has invalid check digit")
+	}
+	return nil
+}
+
 // PeriodType is the type of tax period.
 type PeriodType string
 
@@ -259,23 +619,23 @@ func validatePeriodType(t PeriodType) error {
 	case PeriodYear, PeriodHalf, PeriodQuarter, PeriodMonth, PeriodDecade, PeriodDay:
 		return nil
 	}
-	return errors.New("period type must be one of: R (year), P (half), K (quarter), M (month), D (decade), J (day)")
+	return newValidationError("PeriodType", RuleInvalidValue, string(t), "period type must be one of: R (year), P (half), K (quarter), M (month), D (decade), J (day)")
 }
 
 func validatePeriodNumber(num string, periodType PeriodType) error {
 	if periodType == "" || periodType == PeriodYear {
 		if num != "" {
-			return errors.New("period number should be empty for yearly periods")
+			return newValidationError("PeriodNumber", RuleInvalidValue, num, "period number should be empty for yearly periods")
 		}
 		return nil
 	}
 
 	if num == "" {
-		return errors.New("period number is required for non-yearly periods")
+		return newValidationError("PeriodNumber", RuleRequired, "", "period number is required for non-yearly periods")
 	}
 
 	if len(num) > 4 || !isDigitsOnly(num) {
-		return errors.New("period number must be 1-4 digits")
+		return newValidationError("PeriodNumber", RuleFormat, num, "period number must be 1-4 digits")
 	}
 
 	n, _ := strconv.Atoi(num)
@@ -283,24 +643,24 @@ func validatePeriodNumber(num string, periodType PeriodType) error {
 	switch periodType {
 	case PeriodHalf:
 		if n < 1 || n > 2 {
-			return errors.New("half-year number must be 01 or 02")
+			return newValidationError("PeriodNumber", RuleInvalidValue, num, "half-year number must be 01 or 02")
 		}
 	case PeriodQuarter:
 		if n < 1 || n > 4 {
-			return errors.New("quarter number must be 01, 02, 03, or 04")
+			return newValidationError("PeriodNumber", RuleInvalidValue, num, "quarter number must be 01, 02, 03, or 04")
 		}
 	case PeriodMonth:
 		if n < 1 || n > 12 {
-			return errors.New("month number must be 01-12")
+			return newValidationError("PeriodNumber", RuleInvalidValue, num, "month number must be 01-12")
 		}
 	case PeriodDecade:
 		if n < 1 || n > 3 {
-			return errors.New("decade number must be 01, 02, or 03")
+			return newValidationError("PeriodNumber", RuleInvalidValue, num, "decade number must be 01, 02, or 03")
 		}
 	case PeriodDay:
 		// Format DDMM, validated separately
 		if len(num) != 4 {
-			return errors.New("day format must be DDMM (4 digits)")
+			return newValidationError("PeriodNumber", RuleFormat, num, "day format must be DDMM (4 digits)")
 		}
 	}
 
@@ -312,7 +672,7 @@ func validateYear(year string) error {
 		return nil // optional
 	}
 	if len(year) != 2 || !isDigitsOnly(year) {
-		return errors.New("year must be 2 digits (e.g., 05 for 2005)")
+		return newValidationError("Year", RuleFormat, year, "year must be 2 digits (e.g., 05 for 2005)")
 	}
 	return nil
 }
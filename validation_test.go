@@ -0,0 +1,382 @@
+package sanpltxt_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zeebo/assert"
+
+	"github.com/amwolff/sanpltxt"
+)
+
+func TestValidateNRB(t *testing.T) {
+	tests := []struct {
+		nrb   string
+		valid bool
+	}{
+		{"51109010430000000100111111", true},
+		{"PL51109010430000000100111111", true},
+		{"51 1090 1043 0000 0001 0011 1111", true},
+		{"pl 20 6000 0002 0260 1111 2222 3333", true},
+		{"51109010430000000100111112", false}, // typo in account digit
+		{"15109010430000000100111111", false}, // swapped check digits
+		{"5110901043000000010011111", false},
+		{"5110901043000000010011111X", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nrb, func(t *testing.T) {
+			err := sanpltxt.ValidateNRB(tt.nrb)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidation_NRBChecksum(t *testing.T) {
+	s := &sanpltxt.Standard{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100017", // last digit mistyped
+		RecipientName: "Test",
+		Address:       "Test",
+		Amount:        100,
+		Mode:          sanpltxt.ModeElixir,
+		Title:         "Test",
+	}
+
+	_, err := s.Marshal()
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "credit account"))
+	assert.True(t, strings.Contains(err.Error(), "expected"))
+}
+
+func TestValidation_InternalModeRequiresSantanderAccount(t *testing.T) {
+	s := &sanpltxt.Standard{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100016", // PKO BP
+		RecipientName: "Test",
+		Address:       "Test",
+		Amount:        100,
+		Mode:          sanpltxt.ModeInternal,
+		Title:         "Test",
+	}
+
+	_, err := s.Marshal()
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "ModeElixir"))
+
+	s.CreditAccount = "51109010430000000100111111"
+	_, err = s.Marshal()
+	assert.NoError(t, err)
+}
+
+func TestValidateNIP(t *testing.T) {
+	tests := []struct {
+		nip   string
+		valid bool
+	}{
+		{"7850000007", true},
+		{"PL7850000007", true},
+		{"PL 785-000-00-07", true},
+		{"785 000 00 07", true},
+		{"7850000000", false}, // wrong check digit
+		{"7850000070", false},
+		{"785000000", false},
+		{"78500000O7", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nip, func(t *testing.T) {
+			err := sanpltxt.ValidateNIP(tt.nip)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	assert.Equal(t, sanpltxt.NormalizeNIP("pl 123-456-78-90"), "1234567890")
+}
+
+func TestValidation_TaxIdentifiers(t *testing.T) {
+	tests := []struct {
+		typ   sanpltxt.IdentifierType
+		id    string
+		valid bool
+	}{
+		{sanpltxt.IdentifierPESEL, "44051401359", true},
+		{sanpltxt.IdentifierPESEL, "02270803624", true},  // born 2002-07-08
+		{sanpltxt.IdentifierPESEL, "44051401358", false}, // wrong check digit
+		{sanpltxt.IdentifierPESEL, "02223001239", false}, // 30 February 2002
+		{sanpltxt.IdentifierPESEL, "4405140135", false},
+		{sanpltxt.IdentifierREGON, "123456785", true},
+		{sanpltxt.IdentifierREGON, "12345678512347", true},
+		{sanpltxt.IdentifierREGON, "123456784", false},
+		{sanpltxt.IdentifierREGON, "12345678512346", false},
+		{sanpltxt.IdentifierREGON, "12345678412347", false}, // invalid REGON-9 prefix
+		{sanpltxt.IdentifierNIP, "9721230108", true},
+		{sanpltxt.IdentifierNIP, "9721230101", false},
+		{sanpltxt.IdentifierNIP, "PL 972-123-01-08", true},
+		{sanpltxt.IdentifierID, "ABA300000", true},
+		{sanpltxt.IdentifierID, "abc 412345", true},
+		{sanpltxt.IdentifierID, "ABC312345", false}, // wrong check digit
+		{sanpltxt.IdentifierID, "AB1412345", false},
+		{sanpltxt.IdentifierID, "ABC41234", false},
+		{sanpltxt.IdentifierPassport, "EA9123456", true},
+		{sanpltxt.IdentifierPassport, "ea 9123456", true},
+		{sanpltxt.IdentifierPassport, "EA8123456", false},
+		{sanpltxt.IdentifierPassport, "E19123456", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.typ)+tt.id, func(t *testing.T) {
+			tax := &sanpltxt.Tax{
+				TaxOffice:      true,
+				DebitAccount:   "51109010430000000100111111",
+				CreditAccount:  "06101014690039392223000000",
+				RecipientName:  "Urzad Skarbowy",
+				Amount:         100000,
+				PayerName:      "Jan Kowalski",
+				IdentifierType: tt.typ,
+				Identifier:     tt.id,
+				FormSymbol:     "PIT5",
+			}
+			_, err := tax.Marshal()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestTax_Marshal_NormalizesIDCard(t *testing.T) {
+	tax := &sanpltxt.Tax{
+		TaxOffice:      true,
+		DebitAccount:   "51109010430000000100111111",
+		CreditAccount:  "06101014690039392223000000",
+		RecipientName:  "Urzad Skarbowy",
+		Amount:         100000,
+		PayerName:      "Jan Kowalski",
+		IdentifierType: sanpltxt.IdentifierID,
+		Identifier:     " aba 300000 ",
+		FormSymbol:     "PIT5",
+	}
+
+	got, err := tax.Marshal()
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(got, "|1|ABA300000|"))
+}
+
+func TestMarshal_NormalizesNIP(t *testing.T) {
+	s := &sanpltxt.Standard{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100016",
+		RecipientName: "Jerzy Kowalski",
+		Address:       "Warszawa ul. Kaliska 123",
+		Amount:        12312,
+		Mode:          sanpltxt.ModeElixir,
+		Title:         "zasielenie konta",
+		NIP:           "PL 785-000-00-07",
+	}
+	got, err := s.Marshal()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(got, "||7850000007|"))
+
+	sp := &sanpltxt.SplitPayment{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "88102055581111103350100011",
+		RecipientName: "Jan Nowak",
+		GrossAmount:   12350,
+		Mode:          sanpltxt.ModeElixir,
+		VATAmount:     2309,
+		RecipientNIP:  "pl8960005673",
+		InvoiceNumber: "5/2018",
+	}
+	got, err = sp.Marshal()
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(got, "/IDC/8960005673/INV/"))
+
+	tax := &sanpltxt.Tax{
+		TaxOffice:      true,
+		DebitAccount:   "51109010430000000100111111",
+		CreditAccount:  "06101014690039392223000000",
+		RecipientName:  "Urzad Skarbowy",
+		Amount:         100000,
+		PayerName:      "Jan Kowalski",
+		IdentifierType: sanpltxt.IdentifierNIP,
+		Identifier:     "972 123 01 08",
+		FormSymbol:     "PIT5",
+	}
+	got, err = tax.Marshal()
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(got, "|N|9721230108|"))
+
+	s.NIP = "PL 785-000-00-08"
+	_, err = s.Marshal()
+	var verr *sanpltxt.ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, verr.Field, "NIP")
+	assert.Equal(t, verr.Rule, sanpltxt.RuleChecksum)
+}
+
+func TestValidation_LimitsCountCharacters(t *testing.T) {
+	p := &sanpltxt.Payroll{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100016",
+		RecipientName: strings.Repeat("Żółć", 20), // 80 characters, 140 bytes
+		Address:       strings.Repeat("ą", 60),
+		Amount:        100012,
+		Mode:          sanpltxt.ModeElixir,
+		Title:         strings.Repeat("Wynagrodzenie za miesiąc", 5) + strings.Repeat("ż", 20), // 140 characters
+	}
+
+	_, err := p.Marshal()
+	assert.NoError(t, err)
+
+	p.RecipientName += "ź"
+	_, err = p.Marshal()
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "got 81"))
+}
+
+func TestValidationError(t *testing.T) {
+	valid := &sanpltxt.Standard{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100016",
+		RecipientName: "Test",
+		Address:       "Test",
+		Amount:        100,
+		Mode:          sanpltxt.ModeElixir,
+		Title:         "Test",
+	}
+	invalid := *valid
+	invalid.Title = strings.Repeat("x", 141)
+
+	_, err := sanpltxt.NewPackage(1, []sanpltxt.Transfer{valid, &invalid}, nil).Marshal()
+
+	var verr *sanpltxt.ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, verr.TransferIndex, 1)
+	assert.Equal(t, verr.TransferType, 1)
+	assert.Equal(t, verr.Field, "Title")
+	assert.Equal(t, verr.Rule, sanpltxt.RuleTooLong)
+	assert.Equal(t, verr.Max, 140)
+	assert.Equal(t, verr.Length, 141)
+	assert.Equal(t, err.Error(), "transfer 1: title must be at most 140 characters, got 141")
+
+	_, err = (&sanpltxt.Tax{TaxOffice: false}).Marshal()
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, verr.TransferIndex, -1)
+	assert.Equal(t, verr.TransferType, 4)
+	assert.Equal(t, verr.Field, "DebitAccount")
+	assert.Equal(t, verr.Rule, sanpltxt.RuleFormat)
+
+	err = sanpltxt.ValidateNIP("7850000000")
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, verr.Rule, sanpltxt.RuleChecksum)
+
+	_, err = sanpltxt.NewPackage(2, []sanpltxt.Transfer{valid}, nil).Marshal()
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, verr.Rule, sanpltxt.RulePackageType)
+	assert.Equal(t, verr.TransferIndex, 0)
+}
+
+func TestPackage_Validate_CollectsAllErrors(t *testing.T) {
+	valid := &sanpltxt.Standard{
+		DebitAccount:  "51109010430000000100111111",
+		CreditAccount: "50102055581111103350100016",
+		RecipientName: "Test",
+		Address:       "Test",
+		Amount:        100,
+		Mode:          sanpltxt.ModeElixir,
+		Title:         "Test",
+	}
+	pkg := sanpltxt.NewPackage(1, []sanpltxt.Transfer{
+		&sanpltxt.Standard{
+			DebitAccount:  "51109010430000000100111111",
+			CreditAccount: "50102055581111103350100016",
+			Address:       "Test|",
+			Amount:        100,
+			Mode:          sanpltxt.ModeElixir,
+			Title:         "Test",
+		},
+		valid,
+		&sanpltxt.Payroll{},
+	}, nil)
+
+	err := pkg.Validate()
+	assert.Error(t, err)
+
+	var verrs sanpltxt.ValidationErrors
+	assert.True(t, errors.As(err, &verrs))
+
+	var got []string
+	for _, e := range verrs {
+		got = append(got, fmt.Sprintf("%d:%s:%s", e.TransferIndex, e.Field, e.Rule))
+	}
+	assert.DeepEqual(t, got, []string{
+		"0:RecipientName:required",
+		"0:Address:invalid_chars",
+		"2::package_type",
+		"2:DebitAccount:invalid_format",
+		"2:CreditAccount:invalid_format",
+		"2:RecipientName:required",
+		"2:Address:required",
+		"2:Amount:not_positive",
+		"2:Title:required",
+	})
+	assert.True(t, strings.HasPrefix(err.Error(), "transfer 0: recipient name is required\ntransfer 0: address"))
+
+	assert.NoError(t, sanpltxt.NewPackage(1, []sanpltxt.Transfer{valid}, nil).Validate())
+}
+
+func TestValidation_Amounts(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  sanpltxt.TransferMode
+		gross sanpltxt.Amount
+		vat   sanpltxt.Amount
+		rule  sanpltxt.Rule
+	}{
+		{"valid", sanpltxt.ModeElixir, 12350, 2309, ""},
+		{"zero", sanpltxt.ModeElixir, 0, 0, sanpltxt.RuleNotPositive},
+		{"negative", sanpltxt.ModeElixir, -50000, 0, sanpltxt.RuleNotPositive},
+		{"too many digits", sanpltxt.ModeElixir, sanpltxt.MaxAmount + 1, 0, sanpltxt.RuleAmountLimit},
+		{"express elixir limit", sanpltxt.ModeExpressElixir, sanpltxt.MaxExpressElixirAmount + 1, 0, sanpltxt.RuleAmountLimit},
+		{"express elixir", sanpltxt.ModeExpressElixir, sanpltxt.MaxExpressElixirAmount, 0, ""},
+		{"sorbnet minimum", sanpltxt.ModeSORBNET, sanpltxt.MinSORBNETAmount - 1, 0, sanpltxt.RuleAmountLimit},
+		{"negative vat", sanpltxt.ModeElixir, 12350, -1, sanpltxt.RuleInvalidValue},
+		{"vat above gross", sanpltxt.ModeElixir, 12350, 12351, sanpltxt.RuleInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &sanpltxt.SplitPayment{
+				DebitAccount:  "51109010430000000100111111",
+				CreditAccount: "88102055581111103350100011",
+				RecipientName: "Jan Nowak",
+				GrossAmount:   tt.gross,
+				Mode:          tt.mode,
+				VATAmount:     tt.vat,
+				RecipientNIP:  "8960005673",
+				InvoiceNumber: "5/2018",
+			}
+			_, err := sp.Marshal()
+			if tt.rule == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *sanpltxt.ValidationError
+			assert.True(t, errors.As(err, &verr))
+			assert.Equal(t, verr.Rule, tt.rule)
+		})
+	}
+}
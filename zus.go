@@ -1,19 +1,20 @@
 package sanpltxt
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
 
 // ZUS is a Type 2 transfer (social insurance payment). Mode is always Elixir.
 type ZUS struct {
-	DebitAccount  string
-	CreditAccount string
-	RecipientName string
-	Address       string
-	Amount        Amount
-	Title         string
-	Date          *time.Time
+	DebitAccount  string     `json:"debit_account"`
+	CreditAccount string     `json:"credit_account"`
+	RecipientName string     `json:"recipient_name"`
+	Address       string     `json:"address"`
+	Amount        Amount     `json:"amount"`
+	Title         string     `json:"title"`
+	Date          *time.Time `json:"date,omitempty"`
 }
 
 var _ Transfer = (*ZUS)(nil)
@@ -21,7 +22,7 @@ var _ Transfer = (*ZUS)(nil)
 // Marshal returns the transfer in Santander format.
 func (z *ZUS) Marshal() (string, error) {
 	var b strings.Builder
-	if err := z.marshal(&b); err != nil {
+	if err := marshalRecord(&b, z); err != nil {
 		return "", err
 	}
 	return b.String(), nil
@@ -29,7 +30,7 @@ func (z *ZUS) Marshal() (string, error) {
 
 func (z *ZUS) marshal(b *strings.Builder) error {
 	if err := z.validate(); err != nil {
-		return err
+		return annotate(err, -1, z)
 	}
 
 	b.WriteString("2|")
@@ -56,20 +57,36 @@ func (z *ZUS) marshal(b *strings.Builder) error {
 }
 
 func (z *ZUS) validate() error {
-	if err := validateNRB(z.DebitAccount, "debit account"); err != nil {
-		return err
-	}
-	if err := validateNRB(z.CreditAccount, "credit account"); err != nil {
+	return joinValidationErrors(
+		validateNRB(z.DebitAccount, "DebitAccount"),
+		validateNRB(z.CreditAccount, "CreditAccount"),
+		validateRecipientName(z.RecipientName),
+		validateAddress(z.Address, true),
+		validateAmount(z.Amount, "Amount"),
+		validateTitle(z.Title),
+	)
+}
+
+func (z *ZUS) unmarshal(f []string) error {
+	if err := checkFieldCount(f, 9); err != nil {
 		return err
 	}
-	if err := validateRecipientName(z.RecipientName); err != nil {
+
+	var err error
+	z.DebitAccount = f[1]
+	z.CreditAccount = f[2]
+	z.RecipientName = f[3]
+	z.Address = f[4]
+	if z.Amount, err = parseAmount(f, 5); err != nil {
 		return err
 	}
-	if err := validateAddress(z.Address, true); err != nil {
-		return err
+	if f[6] != ModeElixir.String() {
+		return fieldError(6, fmt.Errorf("ZUS transfers must use mode %s, got %q", ModeElixir, f[6]))
 	}
-	if err := validateTitle(z.Title); err != nil {
+	z.Title = f[7]
+	if z.Date, err = parseDate(f, 8); err != nil {
 		return err
 	}
+
 	return nil
 }